@@ -0,0 +1,298 @@
+// Package compress provides a middleware that compresses HTTP responses
+// with gzip or deflate negotiated by the Accept-Encoding request header
+// and decompresses gzip encoded request bodies.
+//
+// Responses are only compressed if their body has at least MinSize bytes
+// and their content type is not already compressed like PNG, JPEG, or Zip.
+// Flushing is passed through, so streaming responses like server-sent events
+// from respond.EventStream are compressed incrementally.
+//
+// Example usage:
+//
+//	mux := http.NewServeMux()
+//	mux.Handle("/api/users", respond.JSON(listUsers))
+//	mux.Handle("/reports/users.csv", respond.CSV(userReport))
+//	http.ListenAndServe(":8080", compress.Handler(mux))
+package compress
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ungerik/go-httpx/contenttype"
+	"github.com/ungerik/go-httpx/httperr"
+)
+
+var (
+	// MinSize is the minimum number of body bytes for
+	// a response to be compressed. Default is 1024 bytes.
+	MinSize = 1024
+
+	// Level is the compression level of gzip and deflate
+	// from 1 (best speed) to 9 (best compression).
+	// Default is gzip.DefaultCompression.
+	Level = gzip.DefaultCompression
+
+	// SkipContentTypes are the content types of responses that are
+	// not compressed because they are already compressed.
+	// Parameters are ignored and a subtype of "*" matches all subtypes.
+	SkipContentTypes = []string{
+		contenttype.PNG,
+		contenttype.GIF,
+		contenttype.JPEG,
+		contenttype.Zip,
+		"application/gzip",
+		"application/x-gzip",
+		"application/zstd",
+		"font/woff",
+		"font/woff2",
+		"image/webp",
+		"image/avif",
+		"audio/*",
+		"video/*",
+	}
+)
+
+// Config configures the compression middleware.
+type Config struct {
+	// MinSize is the minimum number of body bytes for a response to be compressed.
+	MinSize int
+
+	// Level is the compression level of gzip and deflate,
+	// gzip.DefaultCompression is used if the level is invalid.
+	Level int
+
+	// SkipContentTypes are the content types of responses that are not compressed.
+	SkipContentTypes []string
+
+	// DecompressRequests controls whether gzip encoded request bodies are
+	// decompressed before they are passed to the wrapped handler.
+	DecompressRequests bool
+}
+
+// DefaultConfig returns a new Config with the current
+// values of the package level configuration variables
+// and DecompressRequests enabled.
+func DefaultConfig() *Config {
+	return &Config{
+		MinSize:            MinSize,
+		Level:              Level,
+		SkipContentTypes:   SkipContentTypes,
+		DecompressRequests: true,
+	}
+}
+
+// Handler returns a http.Handler that compresses the responses of next
+// and decompresses gzip encoded request bodies using DefaultConfig.
+func Handler(next http.Handler) http.Handler {
+	return DefaultConfig().Handler(next)
+}
+
+// Handler returns a http.Handler that compresses the responses of next
+// with the encoding that best matches the Accept-Encoding header
+// of the request, respecting q-values. gzip is preferred over deflate.
+//
+// Compressed responses have a Content-Encoding header, no Content-Length,
+// and strong ETags are converted to weak ones because the compressed
+// body is not byte-identical to the uncompressed one.
+// Responses to HEAD requests are not compressed, but get the same
+// weak ETag whenever an encoding was negotiated.
+// Vary: Accept-Encoding is added to all responses with a content type
+// that can be compressed, regardless of the status code like
+// 304 Not Modified and also if the request accepts no compression.
+//
+// If DecompressRequests of the Config is true, then request bodies
+// with Content-Encoding gzip are decompressed and invalid gzip data
+// results in a 400 Bad Request error handled by httperr.Handle.
+// The limits of decoders like respond.MaxRequestBodySize apply
+// to the decompressed body.
+func (c *Config) Handler(next http.Handler) http.Handler {
+	level := c.Level
+	if level < gzip.HuffmanOnly || level > gzip.BestCompression {
+		level = gzip.DefaultCompression
+	}
+	h := &handler{config: c, next: next}
+	h.gzipPool.New = func() any {
+		w, _ := gzip.NewWriterLevel(nil, level)
+		return w
+	}
+	h.deflatePool.New = func() any {
+		w, _ := zlib.NewWriterLevel(nil, level)
+		return w
+	}
+	return h
+}
+
+type handler struct {
+	config      *Config
+	next        http.Handler
+	gzipPool    sync.Pool
+	deflatePool sync.Pool
+}
+
+func (h *handler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	if h.config.DecompressRequests {
+		err := decompressRequest(request)
+		if httperr.Handle(err, writer, request) {
+			return
+		}
+	}
+
+	w := &compressWriter{
+		ResponseWriter: writer,
+		handler:        h,
+		encoding:       NegotiateEncoding(request.Header.Get("Accept-Encoding")),
+		head:           request.Method == http.MethodHead,
+	}
+	defer func() {
+		// Don't finish the response of a panicking handler,
+		// so that an incomplete response is aborted by the server
+		if recovered := recover(); recovered != nil {
+			panic(recovered)
+		}
+		w.close()
+	}()
+
+	h.next.ServeHTTP(w, request)
+}
+
+// compressor is implemented by *gzip.Writer and *zlib.Writer
+type compressor interface {
+	io.WriteCloser
+	Flush() error
+	Reset(io.Writer)
+}
+
+func (h *handler) getCompressor(encoding string, writer io.Writer) compressor {
+	var c compressor
+	switch encoding {
+	case "gzip":
+		c = h.gzipPool.Get().(*gzip.Writer)
+	default:
+		c = h.deflatePool.Get().(*zlib.Writer)
+	}
+	c.Reset(writer)
+	return c
+}
+
+func (h *handler) putCompressor(c compressor) {
+	c.Reset(io.Discard)
+	switch x := c.(type) {
+	case *gzip.Writer:
+		h.gzipPool.Put(x)
+	case *zlib.Writer:
+		h.deflatePool.Put(x)
+	}
+}
+
+func (h *handler) skipContentType(contentType string) bool {
+	mediaType := contenttype.MediaType(contentType)
+	for _, skip := range h.config.SkipContentTypes {
+		skip = contenttype.MediaType(skip)
+		if skip == mediaType {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(skip, "/*"); ok && strings.HasPrefix(mediaType, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// NegotiateEncoding returns "gzip", "deflate", or an empty string
+// for no compression, depending on which encoding has the highest
+// q-value in the passed Accept-Encoding header value.
+// gzip is preferred if both encodings have the same q-value
+// and "*" matches encodings that are not listed.
+// An encoding with a q-value of zero is not acceptable.
+func NegotiateEncoding(acceptEncoding string) string {
+	if acceptEncoding == "" {
+		return ""
+	}
+	qualities := parseAcceptEncoding(acceptEncoding)
+	best, bestQ := "", 0.0
+	for _, encoding := range []string{"gzip", "deflate"} {
+		if q := encodingQuality(qualities, encoding); q > bestQ {
+			best, bestQ = encoding, q
+		}
+	}
+	return best
+}
+
+// AcceptsEncoding returns true if the passed Accept-Encoding
+// header value lists encoding or "*" with a q-value above zero.
+func AcceptsEncoding(acceptEncoding, encoding string) bool {
+	if acceptEncoding == "" {
+		return false
+	}
+	return encodingQuality(parseAcceptEncoding(acceptEncoding), strings.ToLower(encoding)) > 0
+}
+
+func encodingQuality(qualities map[string]float64, encoding string) float64 {
+	if q, ok := qualities[encoding]; ok {
+		return q
+	}
+	return qualities["*"]
+}
+
+// parseAcceptEncoding returns the q-values of the
+// content codings of an Accept-Encoding header value
+func parseAcceptEncoding(acceptEncoding string) map[string]float64 {
+	qualities := make(map[string]float64)
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		coding, params, _ := strings.Cut(part, ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding == "x-gzip" {
+			coding = "gzip"
+		}
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			key, value, _ := strings.Cut(param, "=")
+			if strings.EqualFold(strings.TrimSpace(key), "q") {
+				if parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		if coding != "" {
+			qualities[coding] = q
+		}
+	}
+	return qualities
+}
+
+// decompressRequest replaces the body of a gzip encoded request
+// with a reader returning the decompressed body.
+// A malformed gzip header results in a logged
+// 400 Bad Request with a generic message.
+func decompressRequest(request *http.Request) error {
+	encoding := strings.ToLower(strings.TrimSpace(request.Header.Get("Content-Encoding")))
+	if encoding != "gzip" && encoding != "x-gzip" || request.Body == nil || request.Body == http.NoBody {
+		return nil
+	}
+	reader, err := gzip.NewReader(request.Body)
+	if err != nil {
+		return httperr.WithStatus(fmt.Errorf("malformed gzip request body: %w", err), http.StatusBadRequest, "Malformed request body")
+	}
+	request.Body = gzipBody{Reader: reader, body: request.Body}
+	request.Header.Del("Content-Encoding")
+	request.Header.Del("Content-Length")
+	request.ContentLength = -1
+	return nil
+}
+
+type gzipBody struct {
+	*gzip.Reader
+	body io.ReadCloser
+}
+
+func (b gzipBody) Close() error {
+	b.Reader.Close() //#nosec G104
+	return b.body.Close()
+}
@@ -0,0 +1,340 @@
+package compress
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func ExampleHandler() {
+	handler := Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		io.WriteString(w, strings.Repeat("id,name\n", 200))
+	}))
+
+	request := httptest.NewRequest(http.MethodGet, "/report.csv", nil)
+	request.Header.Set("Accept-Encoding", "deflate;q=0.5, gzip")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	fmt.Println(recorder.Header().Get("Content-Encoding"), recorder.Header().Get("Vary"))
+	compressedSize := recorder.Body.Len()
+	reader, _ := gzip.NewReader(recorder.Body)
+	body, _ := io.ReadAll(reader)
+	fmt.Println(len(body), compressedSize < 100)
+
+	// Output:
+	// gzip Accept-Encoding
+	// 1600 true
+}
+
+func TestHandler(t *testing.T) {
+	large := strings.Repeat("a", 2048)
+	tests := []struct {
+		name           string
+		method         string
+		acceptEncoding string
+		handler        http.HandlerFunc
+		wantEncoding   string
+		wantVary       string
+		wantETag       string
+	}{
+		{
+			name:           "large body",
+			acceptEncoding: "gzip",
+			handler:        func(w http.ResponseWriter, r *http.Request) { io.WriteString(w, large) },
+			wantEncoding:   "gzip",
+			wantVary:       "Accept-Encoding",
+		},
+		{
+			name:           "deflate",
+			acceptEncoding: "deflate",
+			handler:        func(w http.ResponseWriter, r *http.Request) { io.WriteString(w, large) },
+			wantEncoding:   "deflate",
+			wantVary:       "Accept-Encoding",
+		},
+		{
+			name:     "no Accept-Encoding",
+			handler:  func(w http.ResponseWriter, r *http.Request) { io.WriteString(w, large) },
+			wantVary: "Accept-Encoding",
+		},
+		{
+			name:           "gzip not acceptable",
+			acceptEncoding: "gzip;q=0, deflate;q=0",
+			handler:        func(w http.ResponseWriter, r *http.Request) { io.WriteString(w, large) },
+			wantVary:       "Accept-Encoding",
+		},
+		{
+			name:           "below MinSize",
+			acceptEncoding: "gzip",
+			handler:        func(w http.ResponseWriter, r *http.Request) { io.WriteString(w, large[:1023]) },
+			wantVary:       "Accept-Encoding",
+		},
+		{
+			name:           "MinSize reached by several writes",
+			acceptEncoding: "gzip",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				io.WriteString(w, large[:512])
+				io.WriteString(w, large[:512])
+			},
+			wantEncoding: "gzip",
+			wantVary:     "Accept-Encoding",
+		},
+		{
+			name:           "flushed below MinSize",
+			acceptEncoding: "gzip",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				io.WriteString(w, "event")
+				w.(http.Flusher).Flush()
+			},
+			wantEncoding: "gzip",
+			wantVary:     "Accept-Encoding",
+		},
+		{
+			name:           "skipped content type",
+			acceptEncoding: "gzip",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "image/png")
+				io.WriteString(w, large)
+			},
+		},
+		{
+			name:           "skipped content type wildcard",
+			acceptEncoding: "gzip",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "video/mp4; codecs=avc1")
+				io.WriteString(w, large)
+			},
+		},
+		{
+			name:           "already encoded",
+			acceptEncoding: "gzip",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Encoding", "br")
+				io.WriteString(w, large)
+			},
+			wantEncoding: "br",
+		},
+		{
+			name:           "no-transform",
+			acceptEncoding: "gzip",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Cache-Control", "no-transform")
+				io.WriteString(w, large)
+			},
+		},
+		{
+			name:           "partial content",
+			acceptEncoding: "gzip",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Range", "bytes 0-2047/4096")
+				w.WriteHeader(http.StatusPartialContent)
+				io.WriteString(w, large)
+			},
+			wantVary: "Accept-Encoding",
+		},
+		{
+			name:           "no content",
+			acceptEncoding: "gzip",
+			handler:        func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) },
+			wantVary:       "Accept-Encoding",
+		},
+		{
+			name:           "not modified",
+			acceptEncoding: "gzip",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusNotModified)
+			},
+			wantVary: "Accept-Encoding",
+		},
+		{
+			name:           "not modified skipped content type",
+			acceptEncoding: "gzip",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "image/png")
+				w.WriteHeader(http.StatusNotModified)
+			},
+		},
+		{
+			name:           "HEAD",
+			method:         http.MethodHead,
+			acceptEncoding: "gzip",
+			handler:        func(w http.ResponseWriter, r *http.Request) { io.WriteString(w, large) },
+			wantVary:       "Accept-Encoding",
+		},
+		{
+			name:           "HEAD strong ETag becomes weak",
+			method:         http.MethodHead,
+			acceptEncoding: "gzip",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("ETag", `"abc"`)
+				io.WriteString(w, large[:10])
+			},
+			wantVary: "Accept-Encoding",
+			wantETag: `W/"abc"`,
+		},
+		{
+			name:   "HEAD without Accept-Encoding keeps strong ETag",
+			method: http.MethodHead,
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("ETag", `"abc"`)
+				io.WriteString(w, large)
+			},
+			wantVary: "Accept-Encoding",
+			wantETag: `"abc"`,
+		},
+		{
+			name:           "strong ETag becomes weak",
+			acceptEncoding: "gzip",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("ETag", `"abc"`)
+				io.WriteString(w, large)
+			},
+			wantEncoding: "gzip",
+			wantVary:     "Accept-Encoding",
+			wantETag:     `W/"abc"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			request := httptest.NewRequest(method, "/", nil)
+			if tt.acceptEncoding != "" {
+				request.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+			recorder := httptest.NewRecorder()
+			Handler(tt.handler).ServeHTTP(recorder, request)
+
+			header := recorder.Header()
+			if got := header.Get("Content-Encoding"); got != tt.wantEncoding {
+				t.Errorf("Content-Encoding = %q, want %q", got, tt.wantEncoding)
+			}
+			if got := header.Get("Vary"); got != tt.wantVary {
+				t.Errorf("Vary = %q, want %q", got, tt.wantVary)
+			}
+			if tt.wantETag != "" && header.Get("ETag") != tt.wantETag {
+				t.Errorf("ETag = %q, want %q", header.Get("ETag"), tt.wantETag)
+			}
+			if tt.wantEncoding == "gzip" {
+				reader, err := gzip.NewReader(recorder.Body)
+				if err != nil {
+					t.Fatalf("gzip.NewReader() error = %v", err)
+				}
+				if _, err := io.ReadAll(reader); err != nil {
+					t.Errorf("reading gzip body: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestHandlerDecompressRequest(t *testing.T) {
+	var compressed bytes.Buffer
+	gzipWriter := gzip.NewWriter(&compressed)
+	io.WriteString(gzipWriter, "hello")
+	gzipWriter.Close()
+
+	tests := []struct {
+		name       string
+		body       []byte
+		wantStatus int
+		wantBody   string
+	}{
+		{"gzip body", compressed.Bytes(), http.StatusOK, "hello"},
+		{"malformed gzip body", []byte("not gzip"), http.StatusBadRequest, "Malformed request body\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				io.Copy(w, r.Body)
+			}))
+			request := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(tt.body))
+			request.Header.Set("Content-Encoding", "gzip")
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+			if got := recorder.Body.String(); got != tt.wantBody {
+				t.Errorf("body = %q, want %q", got, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestHandlerPanic(t *testing.T) {
+	handler := Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, "partial")
+		panic(http.ErrAbortHandler)
+	}))
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	request.Header.Set("Accept-Encoding", "gzip")
+	recorder := httptest.NewRecorder()
+	func() {
+		defer func() {
+			if r := recover(); r != http.ErrAbortHandler {
+				t.Errorf("recovered %v, want http.ErrAbortHandler", r)
+			}
+		}()
+		handler.ServeHTTP(recorder, request)
+	}()
+	if recorder.Body.Len() > 0 || recorder.Header().Get("Content-Encoding") != "" {
+		t.Errorf("response of panicking handler was finished: %q", recorder.Body.String())
+	}
+}
+
+func TestHandlerHijack(t *testing.T) {
+	tests := []struct {
+		name    string
+		writer  http.ResponseWriter
+		wantErr error
+	}{
+		{"hijackable", &hijackRecorder{ResponseRecorder: httptest.NewRecorder()}, nil},
+		{"not hijackable", httptest.NewRecorder(), http.ErrNotSupported},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var hijackErr error
+			handler := Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				io.WriteString(w, "buffered")
+				hijacker, ok := w.(http.Hijacker)
+				if !ok {
+					t.Fatal("compressing writer does not implement http.Hijacker")
+				}
+				_, _, hijackErr = hijacker.Hijack()
+			}))
+			request := httptest.NewRequest(http.MethodGet, "/", nil)
+			request.Header.Set("Accept-Encoding", "gzip")
+			handler.ServeHTTP(tt.writer, request)
+			if !errors.Is(hijackErr, tt.wantErr) {
+				t.Fatalf("Hijack() error = %v, want %v", hijackErr, tt.wantErr)
+			}
+			if recorder, ok := tt.writer.(*hijackRecorder); ok && recorder.Body.Len() > 0 {
+				t.Errorf("wrote %q after hijacking", recorder.Body.String())
+			}
+		})
+	}
+}
+
+type hijackRecorder struct {
+	*httptest.ResponseRecorder
+}
+
+func (r *hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	server, client := net.Pipe()
+	client.Close()
+	return server, bufio.NewReadWriter(bufio.NewReader(server), bufio.NewWriter(server)), nil
+}
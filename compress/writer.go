@@ -0,0 +1,215 @@
+package compress
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/ungerik/go-httpx/contenttype"
+)
+
+// compressWriter buffers the start of the response body
+// until it knows whether the response should be compressed
+type compressWriter struct {
+	http.ResponseWriter
+	handler  *handler
+	encoding string
+	head     bool
+
+	statusCode  int
+	decided     bool
+	buf         []byte
+	compressor  compressor
+	wroteHeader bool
+	hijacked    bool
+}
+
+func (w *compressWriter) WriteHeader(statusCode int) {
+	if statusCode < 200 {
+		w.ResponseWriter.WriteHeader(statusCode)
+		return
+	}
+	if w.statusCode != 0 || w.decided {
+		return
+	}
+	w.statusCode = statusCode
+	if !w.canHaveBody() {
+		w.decide(false) //#nosec G104
+	}
+}
+
+func (w *compressWriter) Write(b []byte) (int, error) {
+	if w.statusCode == 0 {
+		w.statusCode = http.StatusOK
+	}
+	if !w.decided {
+		w.buf = append(w.buf, b...)
+		if len(w.buf) < w.handler.config.MinSize {
+			return len(b), nil
+		}
+		if err := w.decide(false); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	}
+	if w.compressor != nil {
+		return w.compressor.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher by deciding about compression
+// with the buffered body and flushing the compressor
+// and the wrapped http.ResponseWriter
+func (w *compressWriter) Flush() {
+	w.FlushError() //#nosec G104
+}
+
+// FlushError flushes like Flush and returns any error
+func (w *compressWriter) FlushError() error {
+	if w.statusCode == 0 {
+		w.statusCode = http.StatusOK
+	}
+	if !w.decided {
+		if err := w.decide(true); err != nil {
+			return err
+		}
+	}
+	if w.compressor != nil {
+		if err := w.compressor.Flush(); err != nil {
+			return err
+		}
+	}
+	return http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Hijack implements http.Hijacker if the wrapped http.ResponseWriter
+// supports it, else http.ErrNotSupported is returned.
+// Nothing is compressed or written after the connection was hijacked.
+func (w *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(w.ResponseWriter).Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+	w.hijacked = true
+	w.decided = true
+	w.buf = nil
+	return conn, rw, nil
+}
+
+// Unwrap returns the wrapped http.ResponseWriter
+// for http.ResponseController
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// decide starts compression if the response is eligible,
+// writes the header, and writes the buffered body.
+// Bodies smaller than MinSize are only compressed when flushing,
+// because the final size of streamed responses is unknown.
+func (w *compressWriter) decide(flushing bool) error {
+	w.decided = true
+	header := w.ResponseWriter.Header()
+	if w.canHaveBody() && header.Get("Content-Type") == "" && len(w.buf) > 0 {
+		header.Set("Content-Type", http.DetectContentType(w.buf))
+	}
+	if w.compressibleContentType() {
+		contenttype.AddVary(header, "Accept-Encoding")
+	}
+	if w.encoding != "" && w.compressible() {
+		switch {
+		case w.head:
+			// The body of the GET response is unknown,
+			// so its ETag is weakened as if it was compressed
+			weakenETag(header)
+		case flushing || len(w.buf) >= w.handler.config.MinSize:
+			header.Set("Content-Encoding", w.encoding)
+			header.Del("Content-Length")
+			weakenETag(header)
+			w.compressor = w.handler.getCompressor(w.encoding, w.ResponseWriter)
+		}
+	}
+	w.writeHeader()
+	if len(w.buf) == 0 {
+		return nil
+	}
+	buf := w.buf
+	w.buf = nil
+	var err error
+	if w.compressor != nil {
+		_, err = w.compressor.Write(buf)
+	} else {
+		_, err = w.ResponseWriter.Write(buf)
+	}
+	return err
+}
+
+func (w *compressWriter) writeHeader() {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	if w.statusCode != 0 {
+		w.ResponseWriter.WriteHeader(w.statusCode)
+	}
+}
+
+func (w *compressWriter) canHaveBody() bool {
+	return w.statusCode != http.StatusNoContent && w.statusCode != http.StatusNotModified
+}
+
+// compressibleContentType returns true if the content type
+// of the response is not already compressed,
+// independent of the status code
+func (w *compressWriter) compressibleContentType() bool {
+	header := w.ResponseWriter.Header()
+	switch {
+	case header.Get("Content-Encoding") != "",
+		strings.Contains(header.Get("Cache-Control"), "no-transform"),
+		w.handler.skipContentType(header.Get("Content-Type")):
+		return false
+	}
+	return true
+}
+
+// compressible returns true if the response has
+// a body that is not already compressed
+func (w *compressWriter) compressible() bool {
+	switch {
+	case !w.canHaveBody(),
+		w.statusCode == http.StatusPartialContent,
+		w.ResponseWriter.Header().Get("Content-Range") != "":
+		return false
+	}
+	return w.compressibleContentType()
+}
+
+// weakenETag converts a strong ETag to a weak one
+// because the compressed body is not byte-identical
+// to the uncompressed one
+func weakenETag(header http.Header) {
+	if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		header.Set("ETag", "W/"+etag)
+	}
+}
+
+// close writes the buffered body and finishes the compression
+// after the wrapped handler returned
+func (w *compressWriter) close() {
+	if w.hijacked {
+		if w.compressor != nil {
+			w.handler.putCompressor(w.compressor)
+			w.compressor = nil
+		}
+		return
+	}
+	if !w.decided && (w.statusCode != 0 || len(w.buf) > 0) {
+		w.decide(false) //#nosec G104
+	}
+	if w.compressor != nil {
+		w.compressor.Close() //#nosec G104
+		w.handler.putCompressor(w.compressor)
+		w.compressor = nil
+	}
+}
@@ -11,18 +11,30 @@ package contenttype
 
 const (
 	// Text content types (all with charset=utf-8)
-	PlainText  = "text/plain; charset=utf-8"       // Plain text content
-	JavaScript = "text/javascript; charset=utf-8"  // JavaScript code
-	HTML       = "text/html; charset=utf-8"        // HTML documents
-	CSV        = "text/csv; charset=utf-8"         // Comma-separated values
+	PlainText  = "text/plain; charset=utf-8"                // Plain text content
+	JavaScript = "text/javascript; charset=utf-8"           // JavaScript code
+	HTML       = "text/html; charset=utf-8"                 // HTML documents
+	CSV        = "text/csv; charset=utf-8"                  // Comma-separated values
+	TSV        = "text/tab-separated-values; charset=utf-8" // Tab-separated values
 
 	// Data serialization formats
-	XML  = "application/xml"                // XML documents
-	JSON = "application/json; charset=utf-8" // JSON data
+	XML         = "application/xml"                 // XML documents
+	JSON        = "application/json; charset=utf-8" // JSON data
+	YAML        = "application/yaml"                // YAML documents (RFC 9512)
+	MessagePack = "application/msgpack"             // MessagePack binary data
+	CBOR        = "application/cbor"                // CBOR binary data (RFC 8949)
+
+	// Streaming formats
+	NDJSON      = "application/x-ndjson" // Newline-delimited JSON values
+	EventStream = "text/event-stream"    // Server-sent events
+
+	// RFC 9457 problem details formats
+	ProblemJSON = "application/problem+json" // Problem details as JSON
+	ProblemXML  = "application/problem+xml"  // Problem details as XML
 
 	// Binary formats
-	PDF         = "application/pdf"         // PDF documents
-	Zip         = "application/zip"         // ZIP archives
+	PDF         = "application/pdf"          // PDF documents
+	Zip         = "application/zip"          // ZIP archives
 	OctetStream = "application/octet-stream" // Generic binary data
 
 	// Form data types
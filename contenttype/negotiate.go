@@ -0,0 +1,128 @@
+package contenttype
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// MediaType returns the lower case media type of contentType
+// without any parameters like charset.
+//
+//	contenttype.MediaType(contenttype.JSON) == "application/json"
+func MediaType(contentType string) string {
+	if i := strings.IndexByte(contentType, ';'); i >= 0 {
+		contentType = contentType[:i]
+	}
+	return strings.ToLower(strings.TrimSpace(contentType))
+}
+
+// Negotiate returns the offer that best matches the passed
+// Accept header value, respecting q-values and wildcards.
+// Offers are compared by their media type without parameters,
+// so contenttype.JSON matches an Accept value of "application/json".
+// If multiple offers are equally acceptable, the first one wins.
+// An empty accept value accepts everything and returns the first offer.
+// If no offer is acceptable, an empty string is returned.
+//
+//	contenttype.Negotiate("application/xml;q=0.9, */*;q=0.1", contenttype.JSON, contenttype.XML) == contenttype.XML
+func Negotiate(accept string, offers ...string) string {
+	if len(offers) == 0 {
+		return ""
+	}
+	if strings.TrimSpace(accept) == "" {
+		return offers[0]
+	}
+	ranges := parseAccept(accept)
+	var (
+		best  string
+		bestQ float64
+	)
+	for _, offer := range offers {
+		q := acceptQuality(ranges, MediaType(offer))
+		if q > bestQ {
+			best = offer
+			bestQ = q
+		}
+	}
+	return best
+}
+
+// AddVary adds field to the Vary header of a negotiated response
+// if it is not already listed and the header is not "*".
+//
+//	contenttype.AddVary(writer.Header(), "Accept")
+func AddVary(header http.Header, field string) {
+	for _, value := range header.Values("Vary") {
+		for _, f := range strings.Split(value, ",") {
+			f = strings.TrimSpace(f)
+			if f == "*" || strings.EqualFold(f, field) {
+				return
+			}
+		}
+	}
+	header.Add("Vary", field)
+}
+
+type mediaRange struct {
+	typ     string
+	subtype string
+	q       float64
+}
+
+// specificity returns 0 for */*, 1 for type/* and 2 for type/subtype
+func (r mediaRange) specificity() int {
+	switch {
+	case r.typ == "*":
+		return 0
+	case r.subtype == "*":
+		return 1
+	default:
+		return 2
+	}
+}
+
+func (r mediaRange) matches(typ, subtype string) bool {
+	return (r.typ == "*" || r.typ == typ) && (r.subtype == "*" || r.subtype == subtype)
+}
+
+func parseAccept(accept string) []mediaRange {
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		typ, subtype, ok := strings.Cut(MediaType(params[0]), "/")
+		if !ok || typ == "" || subtype == "" {
+			continue
+		}
+		r := mediaRange{typ: typ, subtype: subtype, q: 1}
+		for _, param := range params[1:] {
+			key, value, _ := strings.Cut(param, "=")
+			if strings.TrimSpace(strings.ToLower(key)) != "q" {
+				continue
+			}
+			q, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err == nil && q >= 0 && q <= 1 {
+				r.q = q
+			}
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges
+}
+
+// acceptQuality returns the q-value of the most
+// specific range matching mediaType or 0 if none matches
+func acceptQuality(ranges []mediaRange, mediaType string) float64 {
+	typ, subtype, _ := strings.Cut(mediaType, "/")
+	var (
+		q           float64
+		specificity = -1
+	)
+	for _, r := range ranges {
+		if r.matches(typ, subtype) && r.specificity() > specificity {
+			q = r.q
+			specificity = r.specificity()
+		}
+	}
+	return q
+}
@@ -0,0 +1,36 @@
+package contenttype
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestAddVary(t *testing.T) {
+	tests := []struct {
+		name  string
+		vary  []string
+		field string
+		want  []string
+	}{
+		{"empty", nil, "Accept", []string{"Accept"}},
+		{"other field", []string{"Origin"}, "Accept", []string{"Origin", "Accept"}},
+		{"already listed", []string{"Accept"}, "Accept", []string{"Accept"}},
+		{"case insensitive", []string{"accept-encoding"}, "Accept-Encoding", []string{"accept-encoding"}},
+		{"in list", []string{"Origin, Accept ,Cookie"}, "Accept", []string{"Origin, Accept ,Cookie"}},
+		{"in second value", []string{"Origin", "Accept"}, "Accept", []string{"Origin", "Accept"}},
+		{"wildcard", []string{"*"}, "Accept", []string{"*"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			for _, v := range tt.vary {
+				header.Add("Vary", v)
+			}
+			AddVary(header, tt.field)
+			if got := header.Values("Vary"); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Vary = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
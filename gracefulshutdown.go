@@ -18,6 +18,10 @@ import (
 //   - The server waits for all handlers to finish (up to the timeout)
 //   - Resources are cleaned up properly
 //
+// Long-lived responses like the server-sent events of respond.EventStream
+// handlers are notified via http.Server.RegisterOnShutdown when the shutdown
+// starts, so that they can end before the timeout.
+//
 // Parameters:
 //   - server: The http.Server to shut down
 //   - signalLog: Optional logger for received signals (can be nil)
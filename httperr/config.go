@@ -7,9 +7,11 @@
 // Key features:
 //   - Pre-defined error responses for common HTTP status codes
 //   - Custom error responses with JSON support
+//   - RFC 9457 problem details responses
+//   - Aggregated validation errors with per-field details
 //   - HTTP redirects as error values
 //   - Sentinel error mapping (e.g., os.ErrNotExist -> 404)
-//   - Error logging control with DontLog wrapper
+//   - Structured error logging via log/slog with DontLog wrapper
 //   - Panic recovery and conversion to errors
 //
 // Example usage:
@@ -43,14 +45,65 @@ var (
 	// DebugShowInternalErrorsInResponse is true.
 	DebugShowInternalErrorsInResponseFormat = "\n%+v"
 
+	// InternalServerErrorsAsProblem controls whether DefaultHandlerImpl
+	// writes internal server errors (500) as RFC 9457 problem details
+	// documents using WriteInternalServerErrorProblem
+	// instead of plain text using WriteInternalServerError.
+	InternalServerErrorsAsProblem bool
+
+	// ValidationErrorStatusCode is the status code used for ValidationErrors,
+	// 422 Unprocessable Entity by default.
+	// Some APIs prefer 400 Bad Request.
+	ValidationErrorStatusCode = http.StatusUnprocessableEntity
+
+	// Logger is used by Handle to log every handled error
+	// for which ShouldLog returns true together with the request
+	// and the status code of the written response.
+	// No errors are logged if Logger is nil, which is the default.
+	//
+	//	httperr.Logger = &httperr.SlogLogger{Logger: slog.Default()}
+	Logger ErrorLogger
+
+	// RequestIDHeaders are the request headers checked in order
+	// by RequestID to find the ID of a request for logging.
+	RequestIDHeaders = []string{"X-Request-ID", "X-Correlation-ID"}
+
 	// DefaultHandler is the error handler used by Handle() and HandlePanic().
 	// It can be replaced with a custom handler to change the default error
 	// handling behavior globally.
 	DefaultHandler Handler = HandlerFunc(DefaultHandlerImpl)
 
+	// Sentinels is the ordered registry of error matchers and handlers
+	// used by WriteHandler after SentinelHandlers.
+	// Use it instead of SentinelHandlers to control which handler wins
+	// for errors wrapping multiple sentinels, to match errors by type,
+	// or to register handlers concurrently at runtime.
+	//
+	// By default, the following mappings are registered
+	// with BuiltinSentinelPriority in this order:
+	//   - *http.MaxBytesError                     -> 413 Request Entity Too Large
+	//   - context.Canceled                        -> 499 Client Closed Request (not logged)
+	//   - timeout of a http.Client request
+	//     or of a dial                            -> 504 Gateway Timeout
+	//   - context.DeadlineExceeded                -> 503 Service Unavailable
+	//   - *url.Error of a http.Client request,
+	//     *net.OpError of a dial, ECONNREFUSED    -> 502 Bad Gateway
+	//
+	// Register handlers with a higher priority to override them:
+	//
+	//	httperr.Sentinels.RegisterSentinel(10, myapp.ErrRateLimited, httperr.New(http.StatusTooManyRequests))
+	//	httperr.Sentinels.Register(0, httperr.MatchAs[*myapp.ValidationError](), httperr.BadRequest)
+	Sentinels = newBuiltinSentinels()
+
 	// SentinelHandlers maps sentinel errors to corresponding http.Handler implementations.
 	// When an error that wraps any key in this map is handled by DefaultHandlerImpl,
 	// the corresponding handler's ServeHTTP method will be called.
+	// This map is checked before Sentinels, so its handlers
+	// take precedence over the built-in handlers of Sentinels.
+	// If an error wraps multiple keys of the map, then the sentinel
+	// with the lexically smallest error message wins.
+	// The map is not safe for concurrent modification,
+	// so it should only be changed during initialization.
 	//
 	// By default, the following mappings are configured:
 	//   - os.ErrNotExist -> 404 Not Found (file not found)
@@ -58,32 +111,30 @@ var (
 	//
 	// You can add custom mappings for your own sentinel errors:
 	//
-	//	httperr.SentinelHandlers[myapp.ErrRateLimited] = http.HandlerFunc(
-	//	    func(w http.ResponseWriter, _ *http.Request) {
-	//	        http.Error(w, "Rate limited", http.StatusTooManyRequests)
-	//	    },
-	//	)
+	//	httperr.SentinelHandlers[myapp.ErrRateLimited] = httperr.New(http.StatusTooManyRequests, "Rate limited")
 	SentinelHandlers = map[error]http.Handler{
-		os.ErrNotExist: http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
-			http.Error(writer, "Requested file not found", http.StatusNotFound)
-		}),
-		sql.ErrNoRows: http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
-			http.Error(writer, "Requested database row not found", http.StatusNotFound)
-		}),
+		os.ErrNotExist: New(http.StatusNotFound, "Requested file not found"),
+		sql.ErrNoRows:  New(http.StatusNotFound, "Requested database row not found"),
 	}
 )
 
 // Pre-defined HTTP error responses for common status codes.
 // These can be returned directly from handlers or wrapped with additional context.
 var (
-	BadRequest       = New(http.StatusBadRequest)       // 400: RFC 7231, 6.5.1
-	Unauthorized     = New(http.StatusUnauthorized)     // 401: RFC 7235, 3.1
-	PaymentRequired  = New(http.StatusPaymentRequired)  // 402: RFC 7231, 6.5.2
-	Forbidden        = New(http.StatusForbidden)        // 403: RFC 7231, 6.5.3
-	NotFound         = New(http.StatusNotFound)         // 404: RFC 7231, 6.5.4
-	MethodNotAllowed = New(http.StatusMethodNotAllowed) // 405: RFC 7231, 6.5.5
+	BadRequest         = New(http.StatusBadRequest)         // 400: RFC 7231, 6.5.1
+	Unauthorized       = New(http.StatusUnauthorized)       // 401: RFC 7235, 3.1
+	PaymentRequired    = New(http.StatusPaymentRequired)    // 402: RFC 7231, 6.5.2
+	Forbidden          = New(http.StatusForbidden)          // 403: RFC 7231, 6.5.3
+	NotFound           = New(http.StatusNotFound)           // 404: RFC 7231, 6.5.4
+	MethodNotAllowed   = New(http.StatusMethodNotAllowed)   // 405: RFC 7231, 6.5.5
+	PreconditionFailed = New(http.StatusPreconditionFailed) // 412: RFC 7232, 4.2
 )
 
+// StatusClientClosedRequest is the non-standard status code 499
+// used for requests that were canceled by the client
+// before a response could be written.
+const StatusClientClosedRequest = 499
+
 // Constants for clarity when returning boolean values from error handlers.
 const (
 	Handled    = true  // Indicates that an error was handled and a response was written
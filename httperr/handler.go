@@ -27,6 +27,8 @@ func (f HandlerFunc) HandleError(err error, writer http.ResponseWriter, request
 
 // Handle processes an error using the DefaultHandler.
 // It returns false if err is nil, otherwise it delegates to DefaultHandler.HandleError.
+// If Logger is not nil and ShouldLog(err) returns true,
+// then the handled error is logged with the written status code.
 //
 // This is the main entry point for error handling in most cases.
 // Use it in handlers that return errors:
@@ -42,11 +44,25 @@ func Handle(err error, writer http.ResponseWriter, request *http.Request) (handl
 	if err == nil {
 		return false
 	}
-	return DefaultHandler.HandleError(err, writer, request)
+	logger := Logger
+	if logger == nil || !ShouldLog(err) {
+		return DefaultHandler.HandleError(err, writer, request)
+	}
+	recorder := &statusRecorder{ResponseWriter: writer}
+	handled = DefaultHandler.HandleError(err, recorder, request)
+	if handled {
+		logger.LogError(request, recorder.statusCode, err)
+	}
+	return handled
 }
 
 // HandlePanic processes a panic value recovered by recover() and handles it as an error.
-// It converts the panic value to an error using AsError and then calls Handle.
+// It converts the panic value to a *PanicError carrying the stack trace
+// and request metadata using NewPanicError and then calls Handle.
+// A nil recoverResult is ignored and results in false.
+//
+// If the panic value is http.ErrAbortHandler, then it is re-panicked
+// so that net/http aborts the response without logging a stack trace.
 //
 // Use this in defer statements to catch panics in HTTP handlers:
 //
@@ -59,7 +75,13 @@ func Handle(err error, writer http.ResponseWriter, request *http.Request) (handl
 //	    // Handler code that might panic
 //	}
 func HandlePanic(recoverResult any, writer http.ResponseWriter, request *http.Request) (handled bool) {
-	return Handle(AsError(recoverResult), writer, request)
+	if recoverResult == nil {
+		return false
+	}
+	if isAbortHandler(recoverResult) {
+		panic(http.ErrAbortHandler)
+	}
+	return Handle(NewPanicError(recoverResult, request), writer, request)
 }
 
 // ForEachHandler tries multiple error handlers in sequence until one handles the error.
@@ -85,7 +107,11 @@ func ForEachHandler(err error, writer http.ResponseWriter, request *http.Request
 
 // DefaultHandlerImpl checks if err unwraps to a http.Handler and calls its ServeHTTP method
 // else it checks if err wrapped any key in SentinelHandlers and calls ServeHTTP of the http.Handler value.
-// In all other cases a 500 Internal Server Error response is written.
+// If err unwraps to a StatusCoder, then its status code is written
+// with the standard status text using RenderError.
+// In all other cases a 500 Internal Server Error response is written
+// using RenderInternalServerError, or as problem details document
+// if InternalServerErrorsAsProblem is true.
 // If DebugShowInternalErrorsInResponse is true, then err.Error() message is added to the response.
 // If err is nil, then no response is written and the function returns false.
 // If an error response was written, then the function returns true.
@@ -98,12 +124,23 @@ func DefaultHandlerImpl(err error, writer http.ResponseWriter, request *http.Req
 		return true
 	}
 
-	WriteInternalServerError(err, writer)
+	var coder StatusCoder
+	if errors.As(err, &coder) {
+		RenderError(writer, request, coder.StatusCode(), "")
+		return true
+	}
+
+	if InternalServerErrorsAsProblem {
+		WriteInternalServerErrorProblem(err, writer, request)
+		return true
+	}
+	RenderInternalServerError(err, writer, request)
 	return true
 }
 
 // WriteHandler checks if err unwraps to a http.Handler and calls its ServeHTTP method
-// else it checks if err wrapped any key in SentinelHandlers and calls ServeHTTP of the http.Handler value.
+// else it checks if err wrapped any key in SentinelHandlers or matches
+// any handler registered in Sentinels and calls ServeHTTP of the http.Handler value.
 // If an error response was written, then the function returns true.
 func WriteHandler(err error, writer http.ResponseWriter, request *http.Request) (responseWritten bool) {
 	if err == nil {
@@ -116,18 +153,20 @@ func WriteHandler(err error, writer http.ResponseWriter, request *http.Request)
 		return true
 	}
 
-	for sentinel, handler := range SentinelHandlers {
-		if errors.Is(err, sentinel) {
-			handler.ServeHTTP(writer, request)
-			return true
-		}
+	if handler, ok := sentinelHandlersHandler(err); ok {
+		handler.ServeHTTP(writer, request)
+		return true
+	}
+
+	if handler, ok := Sentinels.Handler(err); ok {
+		handler.ServeHTTP(writer, request)
+		return true
 	}
 
 	return false
 }
 
 // WriteInternalServerError writes err as 500 Internal Server Error reponse.
-// If Logger is not nil, then it will be used to log an error message.
 // If DebugShowInternalErrorsInResponse is true, then the error message
 // will be shown in the response body, else only "Internal Server Error" will be used.
 func WriteInternalServerError(err any, writer http.ResponseWriter) {
@@ -137,3 +176,15 @@ func WriteInternalServerError(err any, writer http.ResponseWriter) {
 	}
 	http.Error(writer, message, http.StatusInternalServerError)
 }
+
+// WriteInternalServerErrorProblem writes err as 500 Internal Server Error
+// RFC 9457 problem details response in the format preferred by the request.
+// If DebugShowInternalErrorsInResponse is true, then the error message
+// will be used as detail of the problem.
+func WriteInternalServerErrorProblem(err any, writer http.ResponseWriter, request *http.Request) {
+	problem := NewProblem(http.StatusInternalServerError)
+	if DebugShowInternalErrorsInResponse {
+		problem.Detail = fmt.Sprintf("%+v", err)
+	}
+	problem.ServeHTTP(writer, request)
+}
@@ -0,0 +1,102 @@
+package httperr
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WithHeader wraps response so that the header key is set
+// to values before response writes itself.
+// Calls can be nested to set multiple headers.
+//
+// Example:
+//
+//	return httperr.WithHeader(httperr.Forbidden, "X-Reason", "account-locked")
+func WithHeader(response Response, key string, values ...string) Response {
+	return responseWithHeader{
+		Response: response,
+		header:   http.Header{http.CanonicalHeaderKey(key): values},
+	}
+}
+
+// TooManyRequests returns a 429 Too Many Requests Response error
+// with a Retry-After header in seconds if retryAfter is greater than zero.
+//
+// Example:
+//
+//	return httperr.TooManyRequests(30 * time.Second)
+func TooManyRequests(retryAfter time.Duration) Response {
+	return withRetryAfter(New(http.StatusTooManyRequests), retryAfter)
+}
+
+// ServiceUnavailable returns a 503 Service Unavailable Response error
+// with a Retry-After header in seconds if retryAfter is greater than zero.
+//
+// Example:
+//
+//	return httperr.ServiceUnavailable(time.Minute)
+func ServiceUnavailable(retryAfter time.Duration) Response {
+	return withRetryAfter(New(http.StatusServiceUnavailable), retryAfter)
+}
+
+// MethodNotAllowedFor returns a 405 Method Not Allowed Response error
+// with an Allow header listing the allowed methods.
+//
+// Example:
+//
+//	return httperr.MethodNotAllowedFor(http.MethodGet, http.MethodHead)
+func MethodNotAllowedFor(methods ...string) Response {
+	return WithHeader(MethodNotAllowed, "Allow", strings.Join(methods, ", "))
+}
+
+// UnauthorizedChallenge returns a 401 Unauthorized Response error
+// with a WWW-Authenticate header for the authentication scheme
+// and the optional realm.
+//
+// Example:
+//
+//	return httperr.UnauthorizedChallenge("Basic", "Admin Area")
+func UnauthorizedChallenge(scheme, realm string) Response {
+	challenge := scheme
+	if realm != "" {
+		challenge += ` realm="` + quotedStringEscaper.Replace(realm) + `"`
+	}
+	return WithHeader(Unauthorized, "WWW-Authenticate", challenge)
+}
+
+// quotedStringEscaper escapes a HTTP quoted-string, see RFC 9110 section 5.6.4
+var quotedStringEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+func withRetryAfter(response Response, retryAfter time.Duration) Response {
+	if retryAfter <= 0 {
+		return response
+	}
+	seconds := int64((retryAfter + time.Second - 1) / time.Second)
+	return WithHeader(response, "Retry-After", strconv.FormatInt(seconds, 10))
+}
+
+type responseWithHeader struct {
+	Response
+	header http.Header
+}
+
+func (r responseWithHeader) problem() *Problem {
+	return AsProblem(r.Response)
+}
+
+func (r responseWithHeader) StatusCode() int {
+	return StatusCode(r.Response)
+}
+
+func (r responseWithHeader) Unwrap() error {
+	return r.Response
+}
+
+func (r responseWithHeader) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	for key, values := range r.header {
+		writer.Header()[key] = values
+	}
+	r.Response.ServeHTTP(writer, request)
+}
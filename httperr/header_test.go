@@ -0,0 +1,74 @@
+package httperr
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func ExampleWithHeader() {
+	errs := []error{
+		TooManyRequests(1500 * time.Millisecond),
+		MethodNotAllowedFor(http.MethodGet, http.MethodHead),
+		UnauthorizedChallenge("Basic", `Admin "Area"`),
+		WithHeader(Forbidden, "X-Reason", "account-locked"),
+	}
+	for _, err := range errs {
+		recorder := httptest.NewRecorder()
+		Handle(err, recorder, httptest.NewRequest(http.MethodPost, "/", nil))
+		header := recorder.Header()
+		header.Del("Content-Type")
+		header.Del("X-Content-Type-Options")
+		header.Del("Vary")
+		fmt.Println(recorder.Code, header)
+	}
+
+	// Output:
+	// 429 map[Retry-After:[2]]
+	// 405 map[Allow:[GET, HEAD]]
+	// 401 map[Www-Authenticate:[Basic realm="Admin \"Area\""]]
+	// 403 map[X-Reason:[account-locked]]
+}
+
+func TestWithHeader(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantHeader http.Header
+	}{
+		{"retry after rounded up", TooManyRequests(1500 * time.Millisecond), 429, http.Header{"Retry-After": {"2"}}},
+		{"retry after whole seconds", ServiceUnavailable(time.Minute), 503, http.Header{"Retry-After": {"60"}}},
+		{"retry after one nanosecond", TooManyRequests(time.Nanosecond), 429, http.Header{"Retry-After": {"1"}}},
+		{"no retry after for zero", TooManyRequests(0), 429, http.Header{}},
+		{"no retry after for negative", ServiceUnavailable(-time.Second), 503, http.Header{}},
+		{"allow without methods", MethodNotAllowedFor(), 405, http.Header{"Allow": {""}}},
+		{"challenge without realm", UnauthorizedChallenge("Bearer", ""), 401, http.Header{"Www-Authenticate": {"Bearer"}}},
+		{"challenge escapes backslash", UnauthorizedChallenge("Basic", `a\b`), 401, http.Header{"Www-Authenticate": {`Basic realm="a\\b"`}}},
+		{"canonical key", WithHeader(Forbidden, "x-reason", "a", "b"), 403, http.Header{"X-Reason": {"a", "b"}}},
+		{"nested headers", WithHeader(TooManyRequests(time.Second), "X-Limit", "10"), 429, http.Header{"Retry-After": {"1"}, "X-Limit": {"10"}}},
+		{"wrapped", fmt.Errorf("rate limit: %w", TooManyRequests(time.Second)), 429, http.Header{"Retry-After": {"1"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			Handle(tt.err, recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+			if code := StatusCode(tt.err); code != tt.wantStatus {
+				t.Errorf("StatusCode() = %d, want %d", code, tt.wantStatus)
+			}
+			header := recorder.Header()
+			header.Del("Content-Type")
+			header.Del("X-Content-Type-Options")
+			header.Del("Vary")
+			if !reflect.DeepEqual(header, tt.wantHeader) {
+				t.Errorf("header = %v, want %v", header, tt.wantHeader)
+			}
+		})
+	}
+}
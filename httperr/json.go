@@ -43,6 +43,14 @@ type statusCodeAndJSON struct {
 	body       any
 }
 
+func (e statusCodeAndJSON) problem() *Problem {
+	return NewProblem(e.statusCode)
+}
+
+func (e statusCodeAndJSON) StatusCode() int {
+	return e.statusCode
+}
+
 func (e statusCodeAndJSON) Error() string {
 	body, err := json.MarshalIndent(e.body, "", "  ")
 	if err != nil {
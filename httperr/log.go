@@ -0,0 +1,194 @@
+package httperr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+)
+
+// ErrorLogger is an interface for logging errors handled by Handle
+// together with the request and the status code of the written response.
+type ErrorLogger interface {
+	LogError(request *http.Request, statusCode int, err error)
+}
+
+// ErrorLoggerFunc is an adapter type that allows ordinary functions to be used as error loggers.
+// It implements the ErrorLogger interface.
+type ErrorLoggerFunc func(request *http.Request, statusCode int, err error)
+
+// LogError implements the ErrorLogger interface for ErrorLoggerFunc.
+func (f ErrorLoggerFunc) LogError(request *http.Request, statusCode int, err error) {
+	f(request, statusCode, err)
+}
+
+// LogLevel returns the default log level for a response status code:
+// slog.LevelError for 5xx, slog.LevelWarn for 429,
+// slog.LevelInfo for other 4xx, and slog.LevelDebug for everything else.
+func LogLevel(statusCode int) slog.Level {
+	switch {
+	case statusCode >= 500:
+		return slog.LevelError
+	case statusCode == http.StatusTooManyRequests:
+		return slog.LevelWarn
+	case statusCode >= 400:
+		return slog.LevelInfo
+	default:
+		return slog.LevelDebug
+	}
+}
+
+// SlogLogger is an ErrorLogger that logs errors
+// as structured records using a *slog.Logger.
+//
+// Example:
+//
+//	httperr.Logger = &httperr.SlogLogger{Logger: slog.Default()}
+type SlogLogger struct {
+	// Logger is used for logging, slog.Default() if nil.
+	Logger *slog.Logger
+	// Level returns the log level for a status code, LogLevel if nil.
+	Level func(statusCode int) slog.Level
+}
+
+// LogError implements the ErrorLogger interface.
+func (l *SlogLogger) LogError(request *http.Request, statusCode int, err error) {
+	logger := l.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	level := LogLevel
+	if l.Level != nil {
+		level = l.Level
+	}
+	ctx := context.Background()
+	if request != nil {
+		ctx = request.Context()
+	}
+	logger.LogAttrs(ctx, level(statusCode), "HTTP error response", logAttrs(request, statusCode, err)...)
+}
+
+// PrintfLogger is an ErrorLogger that logs errors as single
+// lines using a Printf method like the one of httpx.Logger
+// or the standard library's log.Logger.
+//
+// Example:
+//
+//	httperr.Logger = httperr.PrintfLogger{Logger: log.Default()}
+type PrintfLogger struct {
+	Logger interface {
+		Printf(format string, args ...any)
+	}
+	// Level returns the log level for a status code, LogLevel if nil.
+	Level func(statusCode int) slog.Level
+}
+
+// LogError implements the ErrorLogger interface.
+func (l PrintfLogger) LogError(request *http.Request, statusCode int, err error) {
+	level := LogLevel
+	if l.Level != nil {
+		level = l.Level
+	}
+	var line string
+	for _, attr := range logAttrs(request, statusCode, err) {
+		line += fmt.Sprintf(" %s=%q", attr.Key, attr.Value.String())
+	}
+	l.Logger.Printf("%s HTTP error response:%s", level(statusCode), line)
+}
+
+// RequestID returns the value of the first header
+// from RequestIDHeaders that is set in the request.
+func RequestID(request *http.Request) string {
+	if request == nil {
+		return ""
+	}
+	for _, header := range RequestIDHeaders {
+		if id := request.Header.Get(header); id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+// StackTracer is implemented by errors that carry
+// a stack trace like PanicError.
+// The stack trace is logged by SlogLogger and PrintfLogger.
+type StackTracer interface {
+	StackTrace() string
+}
+
+// ErrorChain returns the messages of err and all errors it wraps,
+// including the branches of errors that wrap multiple errors.
+func ErrorChain(err error) []string {
+	var chain []string
+	var walk func(error)
+	walk = func(e error) {
+		for e != nil {
+			chain = append(chain, fmt.Sprintf("%T: %s", e, e))
+			switch x := e.(type) {
+			case interface{ Unwrap() error }:
+				e = x.Unwrap()
+			case interface{ Unwrap() []error }:
+				for _, branch := range x.Unwrap() {
+					walk(branch)
+				}
+				return
+			default:
+				return
+			}
+		}
+	}
+	walk(err)
+	return chain
+}
+
+func logAttrs(request *http.Request, statusCode int, err error) []slog.Attr {
+	attrs := make([]slog.Attr, 0, 8)
+	if request != nil {
+		attrs = append(attrs,
+			slog.String("method", request.Method),
+			slog.String("path", request.URL.Path),
+		)
+	}
+	attrs = append(attrs, slog.Int("status", statusCode))
+	if id := RequestID(request); id != "" {
+		attrs = append(attrs, slog.String("request_id", id))
+	}
+	attrs = append(attrs,
+		slog.String("error", err.Error()),
+		slog.Any("error_chain", ErrorChain(err)),
+	)
+	var stackTracer StackTracer
+	if errors.As(err, &stackTracer) {
+		attrs = append(attrs, slog.String("stack", stackTracer.StackTrace()))
+	}
+	return attrs
+}
+
+// statusRecorder records the status code written
+// to the wrapped http.ResponseWriter
+type statusRecorder struct {
+	http.ResponseWriter
+	statusCode int
+}
+
+func (r *statusRecorder) WriteHeader(statusCode int) {
+	if r.statusCode == 0 {
+		r.statusCode = statusCode
+	}
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.statusCode == 0 {
+		r.statusCode = http.StatusOK
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+// Unwrap returns the wrapped http.ResponseWriter
+// for http.ResponseController
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,183 @@
+package httperr
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func ExampleSlogLogger() {
+	Logger = &SlogLogger{Logger: slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))}
+	defer func() { Logger = nil }()
+
+	request := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	request.Header.Set("X-Request-ID", "abc")
+
+	Handle(fmt.Errorf("loading user: %w", NotFound), httptest.NewRecorder(), request)
+	Handle(DontLog(NotFound), httptest.NewRecorder(), request)
+
+	// Output:
+	// level=INFO msg="HTTP error response" method=GET path=/users/1 status=404 request_id=abc error="loading user: Not Found" error_chain="[*fmt.wrapError: loading user: Not Found httperr.statusCodeAndText: Not Found]"
+}
+
+func TestLogLevel(t *testing.T) {
+	tests := []struct {
+		statusCode int
+		want       slog.Level
+	}{
+		{http.StatusOK, slog.LevelDebug},
+		{http.StatusFound, slog.LevelDebug},
+		{http.StatusBadRequest, slog.LevelInfo},
+		{http.StatusNotFound, slog.LevelInfo},
+		{http.StatusTooManyRequests, slog.LevelWarn},
+		{StatusClientClosedRequest, slog.LevelInfo},
+		{http.StatusInternalServerError, slog.LevelError},
+		{http.StatusGatewayTimeout, slog.LevelError},
+	}
+	for _, tt := range tests {
+		t.Run(strconv.Itoa(tt.statusCode), func(t *testing.T) {
+			if got := LogLevel(tt.statusCode); got != tt.want {
+				t.Errorf("LogLevel(%d) = %s, want %s", tt.statusCode, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestErrorChain(t *testing.T) {
+	base := errors.New("base")
+	tests := []struct {
+		name string
+		err  error
+		want []string
+	}{
+		{"nil", nil, nil},
+		{"single", base, []string{"*errors.errorString: base"}},
+		{"wrapped", fmt.Errorf("outer: %w", base), []string{"*fmt.wrapError: outer: base", "*errors.errorString: base"}},
+		{"joined", errors.Join(base, NotFound), []string{"*errors.joinError: base\nNot Found", "*errors.errorString: base", "httperr.statusCodeAndText: Not Found"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ErrorChain(tt.err); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ErrorChain() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleLogsError(t *testing.T) {
+	type logged struct {
+		statusCode int
+		err        error
+	}
+	var entries []logged
+	defer func(logger ErrorLogger) { Logger = logger }(Logger)
+	Logger = ErrorLoggerFunc(func(request *http.Request, statusCode int, err error) {
+		entries = append(entries, logged{statusCode, err})
+	})
+
+	tests := []struct {
+		name       string
+		err        error
+		wantLogged bool
+		wantStatus int
+	}{
+		{"error response", NotFound, true, http.StatusNotFound},
+		{"internal error", errors.New("bug"), true, http.StatusInternalServerError},
+		{"DontLog", DontLog(NotFound), false, 0},
+		{"wrapped DontLog", fmt.Errorf("x: %w", DontLog(errors.New("bug"))), false, 0},
+		{"canceled", fmt.Errorf("query: %w", context.Canceled), false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries = nil
+			Handle(tt.err, httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+			if (len(entries) > 0) != tt.wantLogged {
+				t.Fatalf("logged %d entries, want logged %t", len(entries), tt.wantLogged)
+			}
+			if tt.wantLogged && entries[0].statusCode != tt.wantStatus {
+				t.Errorf("logged status %d, want %d", entries[0].statusCode, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestPrintfLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := PrintfLogger{Logger: log.New(&buf, "", 0)}
+	request := httptest.NewRequest(http.MethodPost, "/orders", nil)
+	request.Header.Set("X-Request-ID", "r1")
+
+	tests := []struct {
+		name       string
+		request    *http.Request
+		statusCode int
+		err        error
+		want       string
+	}{
+		{
+			name:       "with request",
+			request:    request,
+			statusCode: http.StatusConflict,
+			err:        New(http.StatusConflict),
+			want:       `INFO HTTP error response: method="POST" path="/orders" status="409" request_id="r1" error="Conflict" error_chain="[httperr.statusCodeAndText: Conflict]"` + "\n",
+		},
+		{
+			name:       "without request",
+			statusCode: http.StatusInternalServerError,
+			err:        errors.New("bug"),
+			want:       `ERROR HTTP error response: status="500" error="bug" error_chain="[*errors.errorString: bug]"` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+			logger.LogError(tt.request, tt.statusCode, tt.err)
+			if buf.String() != tt.want {
+				t.Errorf("logged\n%s\nwant\n%s", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    string
+	}{
+		{"none", nil, ""},
+		{"X-Request-ID", map[string]string{"X-Request-ID": "a"}, "a"},
+		{"X-Correlation-ID", map[string]string{"X-Correlation-ID": "b"}, "b"},
+		{"first header wins", map[string]string{"X-Request-ID": "a", "X-Correlation-ID": "b"}, "a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/", nil)
+			for key, value := range tt.headers {
+				request.Header.Set(key, value)
+			}
+			if got := RequestID(request); got != tt.want {
+				t.Errorf("RequestID() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	if RequestID(nil) != "" {
+		t.Error("RequestID(nil) is not empty")
+	}
+}
@@ -0,0 +1,97 @@
+package httperr
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+)
+
+// PanicError is the error created by HandlePanic from a recovered panic value.
+// It carries the goroutine stack at the point of recovery and
+// metadata of the request that was handled when the panic occurred.
+//
+// PanicError unwraps to the recovered value if it is an error,
+// so a panic with a Response like NotFound will still write that response.
+// Use errors.As to find out if an error originated from a panic:
+//
+//	var panicErr *httperr.PanicError
+//	if errors.As(err, &panicErr) {
+//	    log.Println(panicErr.Value, panicErr.StackTrace())
+//	}
+//
+// The stack is logged by SlogLogger and PrintfLogger and only shown in
+// internal server error responses if DebugShowInternalErrorsInResponse is true.
+type PanicError struct {
+	// Value is the value passed to panic
+	Value any
+	// Stack is the goroutine stack at the point of recovery
+	Stack []byte
+	// Method of the request
+	Method string
+	// URL of the request
+	URL string
+	// RequestID of the request, see RequestID function
+	RequestID string
+}
+
+// NewPanicError returns a PanicError for the recovered value
+// with the current goroutine stack and metadata from request.
+// If recovered is already a *PanicError, then it is returned unchanged.
+// A nil recovered value results in nil.
+func NewPanicError(recovered any, request *http.Request) *PanicError {
+	if recovered == nil {
+		return nil
+	}
+	if panicErr, ok := recovered.(*PanicError); ok {
+		return panicErr
+	}
+	panicErr := &PanicError{
+		Value: recovered,
+		Stack: debug.Stack(),
+	}
+	if request != nil {
+		panicErr.Method = request.Method
+		panicErr.URL = request.URL.String()
+		panicErr.RequestID = RequestID(request)
+	}
+	return panicErr
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %s", AsError(e.Value))
+}
+
+// Unwrap returns the recovered value if it is an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// StackTrace returns the goroutine stack at the point of recovery.
+// It implements the StackTracer interface.
+func (e *PanicError) StackTrace() string {
+	return string(e.Stack)
+}
+
+// Format implements fmt.Formatter.
+// The %+v verb formats the error message followed by the stack trace,
+// all other verbs format only the error message.
+func (e *PanicError) Format(f fmt.State, verb rune) {
+	switch {
+	case verb == 'v' && f.Flag('+'):
+		fmt.Fprintf(f, "%s\n%s", e.Error(), e.Stack) //#nosec G104
+	case verb == 'q':
+		fmt.Fprintf(f, "%q", e.Error()) //#nosec G104
+	default:
+		fmt.Fprint(f, e.Error()) //#nosec G104
+	}
+}
+
+// isAbortHandler returns true if the recovered value
+// is http.ErrAbortHandler, which must be re-panicked
+// so that net/http aborts the response.
+func isAbortHandler(recovered any) bool {
+	err, ok := recovered.(error)
+	return ok && errors.Is(err, http.ErrAbortHandler)
+}
@@ -0,0 +1,155 @@
+package httperr
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func ExampleHandlePanic() {
+	for _, debug := range []bool{false, true} {
+		DebugShowInternalErrorsInResponse = debug
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		func() {
+			defer func() {
+				HandlePanic(recover(), recorder, request)
+			}()
+			panic("something went wrong")
+		}()
+		fmt.Println(recorder.Code, strings.Contains(recorder.Body.String(), "goroutine"))
+	}
+	DebugShowInternalErrorsInResponse = false
+
+	// Output:
+	// 500 false
+	// 500 true
+}
+
+func ExamplePanicError() {
+	recorder := httptest.NewRecorder()
+	var handled error
+	func() {
+		defer func() {
+			handled = NewPanicError(recover(), nil)
+			Handle(handled, recorder, nil)
+		}()
+		panic(NotFound)
+	}()
+	var panicErr *PanicError
+	fmt.Println(errors.As(handled, &panicErr), panicErr.Value == NotFound, recorder.Code)
+
+	// Output:
+	// true true 404
+}
+
+func TestNewPanicError(t *testing.T) {
+	request := httptest.NewRequest(http.MethodPut, "/items/1?x=y", nil)
+	request.Header.Set("X-Request-ID", "r1")
+	existing := &PanicError{Value: "existing"}
+	tests := []struct {
+		name        string
+		recovered   any
+		request     *http.Request
+		wantNil     bool
+		wantSame    bool
+		wantMessage string
+		wantUnwrap  error
+		wantMethod  string
+	}{
+		{name: "nil", recovered: nil, wantNil: true},
+		{name: "string", recovered: "boom", request: request, wantMessage: "panic: boom", wantMethod: http.MethodPut},
+		{name: "error", recovered: NotFound, wantMessage: "panic: Not Found", wantUnwrap: NotFound},
+		{name: "int", recovered: 42, wantMessage: "panic: 42"},
+		{name: "existing PanicError", recovered: existing, wantSame: true, wantMessage: "panic: existing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewPanicError(tt.recovered, tt.request)
+			if tt.wantNil {
+				if got != nil {
+					t.Errorf("NewPanicError() = %v, want nil", got)
+				}
+				return
+			}
+			if (got == existing) != tt.wantSame {
+				t.Errorf("returned the recovered *PanicError: %t, want %t", got == existing, tt.wantSame)
+			}
+			if got.Error() != tt.wantMessage {
+				t.Errorf("Error() = %q, want %q", got.Error(), tt.wantMessage)
+			}
+			if got.Unwrap() != tt.wantUnwrap {
+				t.Errorf("Unwrap() = %v, want %v", got.Unwrap(), tt.wantUnwrap)
+			}
+			if got.Method != tt.wantMethod {
+				t.Errorf("Method = %q, want %q", got.Method, tt.wantMethod)
+			}
+			if tt.request != nil && (got.URL != "/items/1?x=y" || got.RequestID != "r1") {
+				t.Errorf("URL = %q, RequestID = %q", got.URL, got.RequestID)
+			}
+			if !tt.wantSame && !strings.Contains(got.StackTrace(), "goroutine") {
+				t.Error("StackTrace() does not contain the goroutine stack")
+			}
+		})
+	}
+}
+
+func TestPanicErrorFormat(t *testing.T) {
+	panicErr := &PanicError{Value: "boom", Stack: []byte("goroutine 1 [running]:")}
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"%s", "panic: boom"},
+		{"%v", "panic: boom"},
+		{"%q", `"panic: boom"`},
+		{"%+v", "panic: boom\ngoroutine 1 [running]:"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			if got := fmt.Sprintf(tt.format, panicErr); got != tt.want {
+				t.Errorf("Sprintf(%q) = %q, want %q", tt.format, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandlePanic(t *testing.T) {
+	tests := []struct {
+		name        string
+		recovered   any
+		wantHandled bool
+		wantStatus  int
+		wantPanic   bool
+	}{
+		{"nil", nil, false, http.StatusOK, false},
+		{"string", "boom", true, http.StatusInternalServerError, false},
+		{"Response", Forbidden, true, http.StatusForbidden, false},
+		{"ErrAbortHandler", http.ErrAbortHandler, false, http.StatusOK, true},
+		{"wrapped ErrAbortHandler", fmt.Errorf("x: %w", http.ErrAbortHandler), false, http.StatusOK, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			var handled, panicked bool
+			func() {
+				defer func() {
+					panicked = recover() == http.ErrAbortHandler
+				}()
+				handled = HandlePanic(tt.recovered, recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+			}()
+			if panicked != tt.wantPanic {
+				t.Errorf("re-panicked http.ErrAbortHandler: %t, want %t", panicked, tt.wantPanic)
+			}
+			if handled != tt.wantHandled {
+				t.Errorf("HandlePanic() = %t, want %t", handled, tt.wantHandled)
+			}
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+		})
+	}
+}
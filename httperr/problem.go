@@ -0,0 +1,297 @@
+package httperr
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/ungerik/go-httpx/contenttype"
+)
+
+// ProblemXMLNamespace is the XML namespace of
+// problem details documents defined by RFC 9457.
+const ProblemXMLNamespace = "urn:ietf:rfc:7807"
+
+// Problem is a RFC 9457 problem details document that implements Response.
+// It is rendered as application/problem+json or application/problem+xml
+// depending on the Accept header of the request.
+//
+// Extensions holds additional members that are rendered
+// next to the standard members of the problem document.
+// Extension members with the name of a standard member are ignored.
+//
+// Example:
+//
+//	return &httperr.Problem{
+//	    Type:       "https://example.com/probs/out-of-credit",
+//	    Title:      "You do not have enough credit.",
+//	    Status:     http.StatusForbidden,
+//	    Detail:     "Your current balance is 30, but that costs 50.",
+//	    Instance:   "/account/12345/msgs/abc",
+//	    Extensions: map[string]any{"balance": 30},
+//	}
+type Problem struct {
+	Type       string         `json:"type,omitempty"     xml:"type,omitempty"`
+	Title      string         `json:"title,omitempty"    xml:"title,omitempty"`
+	Status     int            `json:"status,omitempty"   xml:"status,omitempty"`
+	Detail     string         `json:"detail,omitempty"   xml:"detail,omitempty"`
+	Instance   string         `json:"instance,omitempty" xml:"instance,omitempty"`
+	Extensions map[string]any `json:"-"                  xml:"-"`
+}
+
+// NewProblem returns a Problem with the passed status code,
+// the standard HTTP status text as title,
+// and the optional detail strings joined with newlines as detail.
+//
+// Example:
+//
+//	return httperr.NewProblem(http.StatusNotFound, "User 123 does not exist")
+func NewProblem(statusCode int, detail ...string) *Problem {
+	return &Problem{
+		Title:  http.StatusText(statusCode),
+		Status: statusCode,
+		Detail: strings.Join(detail, "\n"),
+	}
+}
+
+// AsProblem converts err to a Problem.
+// If err wraps a *Problem then it is returned unchanged.
+// Errors created with New, Errorf, WithStatus, NewFromResponse, JSON, and Redirect
+// including the predefined values like BadRequest or NotFound are converted
+// to a Problem with their status code and public text.
+// Errors matched by Sentinels, SentinelHandlers or implementing StatusCoder
+// are converted to a Problem with the status code returned by StatusCode.
+// All other errors are converted to a 500 Internal Server Error problem
+// that only includes the error message as detail if
+// DebugShowInternalErrorsInResponse is true.
+// A nil error results in nil.
+func AsProblem(err error) *Problem {
+	if err == nil {
+		return nil
+	}
+	var converter problemConverter
+	if errors.As(err, &converter) {
+		return converter.problem()
+	}
+	if statusCode := StatusCode(err); statusCode != http.StatusInternalServerError {
+		return NewProblem(statusCode)
+	}
+	problem := NewProblem(http.StatusInternalServerError)
+	if DebugShowInternalErrorsInResponse {
+		problem.Detail = fmt.Sprintf("%+v", err)
+	}
+	return problem
+}
+
+// problemConverter is implemented by
+// the Response types of this package
+type problemConverter interface {
+	error
+	problem() *Problem
+}
+
+// newProblemWithText returns a Problem with statusCode and
+// text as detail if it differs from the standard status text
+func newProblemWithText(statusCode int, text string) *Problem {
+	problem := NewProblem(statusCode)
+	if text != "" && text != problem.Title {
+		problem.Detail = text
+	}
+	return problem
+}
+
+// StatusCode returns Status or 500 Internal Server Error if Status is zero.
+func (p *Problem) StatusCode() int {
+	if p.Status == 0 {
+		return http.StatusInternalServerError
+	}
+	return p.Status
+}
+
+func (p *Problem) problem() *Problem {
+	return p
+}
+
+func (p *Problem) Error() string {
+	title := p.Title
+	if title == "" {
+		title = http.StatusText(p.StatusCode())
+	}
+	if p.Detail == "" {
+		return title
+	}
+	return title + ": " + p.Detail
+}
+
+// ServeHTTP writes the problem as application/problem+xml if the
+// Accept header of the request prefers XML, else as application/problem+json.
+func (p *Problem) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	var accept string
+	if request != nil {
+		accept = request.Header.Get("Accept")
+	}
+	switch contenttype.Negotiate(accept, contenttype.ProblemJSON, contenttype.JSON, contenttype.ProblemXML, contenttype.XML) {
+	case contenttype.ProblemXML, contenttype.XML:
+		WriteProblemXML(p, writer)
+	default:
+		WriteProblemJSON(p, writer)
+	}
+}
+
+// WriteProblemJSON writes problem as application/problem+json response.
+// If problem could not be marshalled as JSON, then an internal server error
+// will be written instead using WriteInternalServerError.
+func WriteProblemJSON(problem *Problem, writer http.ResponseWriter) {
+	body, err := json.MarshalIndent(problem, "", "  ")
+	if err != nil {
+		WriteInternalServerError(fmt.Errorf("can't marshall problem as JSON because: %w", err), writer)
+		return
+	}
+	writer.Header().Set("Content-Type", contenttype.ProblemJSON)
+	writer.Header().Set("X-Content-Type-Options", "nosniff")
+	writer.WriteHeader(problem.StatusCode())
+	writer.Write(body) //#nosec G104
+}
+
+// WriteProblemXML writes problem as application/problem+xml response.
+// If problem could not be marshalled as XML, then an internal server error
+// will be written instead using WriteInternalServerError.
+func WriteProblemXML(problem *Problem, writer http.ResponseWriter) {
+	body, err := xml.MarshalIndent(problem, "", "  ")
+	if err != nil {
+		WriteInternalServerError(fmt.Errorf("can't marshall problem as XML because: %w", err), writer)
+		return
+	}
+	writer.Header().Set("Content-Type", contenttype.ProblemXML)
+	writer.Header().Set("X-Content-Type-Options", "nosniff")
+	writer.WriteHeader(problem.StatusCode())
+	writer.Write([]byte(xml.Header)) //#nosec G104
+	writer.Write(body)               //#nosec G104
+}
+
+// problemMembers is used to marshal the standard
+// members of a Problem without recursion
+type problemMembers Problem
+
+var problemMemberNames = map[string]bool{
+	"type":     true,
+	"title":    true,
+	"status":   true,
+	"detail":   true,
+	"instance": true,
+}
+
+// MarshalJSON implements json.Marshaler by writing
+// the standard members followed by the extension members
+// sorted by name.
+func (p Problem) MarshalJSON() ([]byte, error) {
+	members, err := json.Marshal(problemMembers(p))
+	if err != nil {
+		return nil, err
+	}
+	names := p.extensionNames()
+	if len(names) == 0 {
+		return members, nil
+	}
+	var buf bytes.Buffer
+	buf.Write(members[:len(members)-1])
+	for i, name := range names {
+		if i > 0 || len(members) > 2 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(name)
+		value, err := json.Marshal(p.Extensions[name])
+		if err != nil {
+			return nil, fmt.Errorf("can't marshall problem extension %q as JSON because: %w", name, err)
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler by reading
+// the standard members into the struct fields and
+// all other members into Extensions.
+func (p *Problem) UnmarshalJSON(data []byte) error {
+	var members problemMembers
+	if err := json.Unmarshal(data, &members); err != nil {
+		return err
+	}
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return err
+	}
+	*p = Problem(members)
+	for name, value := range all {
+		if problemMemberNames[name] {
+			continue
+		}
+		var ext any
+		if err := json.Unmarshal(value, &ext); err != nil {
+			return err
+		}
+		if p.Extensions == nil {
+			p.Extensions = make(map[string]any)
+		}
+		p.Extensions[name] = ext
+	}
+	return nil
+}
+
+// MarshalXML implements xml.Marshaler by writing a problem
+// element in the ProblemXMLNamespace with the standard members
+// followed by the extension members sorted by name.
+func (p Problem) MarshalXML(enc *xml.Encoder, _ xml.StartElement) error {
+	start := xml.StartElement{
+		Name: xml.Name{Local: "problem"},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: ProblemXMLNamespace}},
+	}
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+	members := []struct {
+		name  string
+		value any
+		empty bool
+	}{
+		{"type", p.Type, p.Type == ""},
+		{"title", p.Title, p.Title == ""},
+		{"status", p.Status, p.Status == 0},
+		{"detail", p.Detail, p.Detail == ""},
+		{"instance", p.Instance, p.Instance == ""},
+	}
+	for _, m := range members {
+		if m.empty {
+			continue
+		}
+		if err := enc.EncodeElement(m.value, xml.StartElement{Name: xml.Name{Local: m.name}}); err != nil {
+			return err
+		}
+	}
+	for _, name := range p.extensionNames() {
+		err := enc.EncodeElement(p.Extensions[name], xml.StartElement{Name: xml.Name{Local: name}})
+		if err != nil {
+			return fmt.Errorf("can't marshall problem extension %q as XML because: %w", name, err)
+		}
+	}
+	return enc.EncodeToken(start.End())
+}
+
+func (p *Problem) extensionNames() []string {
+	names := make([]string, 0, len(p.Extensions))
+	for name := range p.Extensions {
+		if !problemMemberNames[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,198 @@
+package httperr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/ungerik/go-httpx/contenttype"
+)
+
+func ExampleAsProblem() {
+	problem := AsProblem(New(http.StatusNotFound, "User 123 does not exist"))
+	problem.Extensions = map[string]any{"userID": 123}
+
+	body, _ := json.Marshal(problem)
+	fmt.Println(string(body))
+
+	var decoded Problem
+	_ = json.Unmarshal(body, &decoded)
+	fmt.Println(decoded.Status, decoded.Extensions["userID"])
+
+	// Output:
+	// {"title":"Not Found","status":404,"detail":"User 123 does not exist","userID":123}
+	// 404 123
+}
+
+func ExampleProblem_ServeHTTP() {
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	request.Header.Set("Accept", "application/xml")
+	recorder := httptest.NewRecorder()
+
+	NewProblem(http.StatusForbidden).ServeHTTP(recorder, request)
+
+	fmt.Println(recorder.Code, recorder.Header().Get("Content-Type"))
+	fmt.Println(recorder.Body.String())
+
+	// Output:
+	// 403 application/problem+xml
+	// <?xml version="1.0" encoding="UTF-8"?>
+	// <problem xmlns="urn:ietf:rfc:7807">
+	//   <title>Forbidden</title>
+	//   <status>403</status>
+	// </problem>
+}
+
+func TestProblemMarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		problem Problem
+		want    string
+		wantErr bool
+	}{
+		{"empty", Problem{}, `{}`, false},
+		{"only extensions", Problem{Extensions: map[string]any{"b": 2, "a": "x"}}, `{"a":"x","b":2}`, false},
+		{"members and extensions", Problem{Status: 400, Extensions: map[string]any{"field": "name"}}, `{"status":400,"field":"name"}`, false},
+		{"extension named like member is ignored", Problem{Status: 400, Extensions: map[string]any{"status": 500}}, `{"status":400}`, false},
+		{"extension with special characters", Problem{Extensions: map[string]any{`a"b`: "<>"}}, `{"a\"b":"\u003c\u003e"}`, false},
+		{"unmarshallable extension", Problem{Extensions: map[string]any{"f": func() {}}}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(tt.problem)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("json.Marshal() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if !tt.wantErr && string(got) != tt.want {
+				t.Errorf("json.Marshal() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProblemUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		want    Problem
+		wantErr bool
+	}{
+		{"empty", `{}`, Problem{}, false},
+		{"members", `{"type":"about:blank","title":"Not Found","status":404,"detail":"d","instance":"/x"}`, Problem{Type: "about:blank", Title: "Not Found", Status: 404, Detail: "d", Instance: "/x"}, false},
+		{"extensions", `{"status":400,"errors":[{"field":"name"}]}`, Problem{Status: 400, Extensions: map[string]any{"errors": []any{map[string]any{"field": "name"}}}}, false},
+		{"invalid status", `{"status":"404"}`, Problem{}, true},
+		{"not an object", `[1]`, Problem{}, true},
+		{"malformed", `{"status":`, Problem{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Problem
+			err := json.Unmarshal([]byte(tt.json), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("json.Unmarshal() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("json.Unmarshal() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAsProblem(t *testing.T) {
+	problem := &Problem{Status: http.StatusPaymentRequired, Extensions: map[string]any{"balance": 30}}
+	tests := []struct {
+		name       string
+		err        error
+		debug      bool
+		wantStatus int
+		wantDetail string
+		wantSame   bool
+	}{
+		{"wrapped Problem", fmt.Errorf("charging: %w", problem), false, http.StatusPaymentRequired, "", true},
+		{"New with text", New(http.StatusNotFound, "User 123 does not exist"), false, http.StatusNotFound, "User 123 does not exist", false},
+		{"predefined", BadRequest, false, http.StatusBadRequest, "", false},
+		{"sentinel", os.ErrNotExist, false, http.StatusNotFound, "", false},
+		{"WithStatus public message", WithStatus(errors.New("db down"), http.StatusBadGateway, "Try again"), false, http.StatusBadGateway, "Try again", false},
+		{"WithStatus hides cause", WithStatus(errors.New("db down"), http.StatusBadGateway), false, http.StatusBadGateway, "", false},
+		{"internal error", errors.New("secret"), false, http.StatusInternalServerError, "", false},
+		{"internal error in debug mode", errors.New("secret"), true, http.StatusInternalServerError, "secret", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func(debug bool) { DebugShowInternalErrorsInResponse = debug }(DebugShowInternalErrorsInResponse)
+			DebugShowInternalErrorsInResponse = tt.debug
+
+			got := AsProblem(tt.err)
+			if got.Status != tt.wantStatus {
+				t.Errorf("Status = %d, want %d", got.Status, tt.wantStatus)
+			}
+			if got.Detail != tt.wantDetail {
+				t.Errorf("Detail = %q, want %q", got.Detail, tt.wantDetail)
+			}
+			if (got == problem) != tt.wantSame {
+				t.Errorf("returned the wrapped *Problem: %t, want %t", got == problem, tt.wantSame)
+			}
+		})
+	}
+	if AsProblem(nil) != nil {
+		t.Error("AsProblem(nil) != nil")
+	}
+}
+
+func TestProblemServeHTTP(t *testing.T) {
+	tests := []struct {
+		accept   string
+		wantType string
+	}{
+		{"", contenttype.ProblemJSON},
+		{"*/*", contenttype.ProblemJSON},
+		{"application/json", contenttype.ProblemJSON},
+		{"application/problem+json", contenttype.ProblemJSON},
+		{"application/xml", contenttype.ProblemXML},
+		{"text/xml", contenttype.ProblemJSON},
+		{"application/problem+xml", contenttype.ProblemXML},
+		{"application/json;q=0.5, application/xml", contenttype.ProblemXML},
+		{"text/html", contenttype.ProblemJSON},
+	}
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/", nil)
+			request.Header.Set("Accept", tt.accept)
+			recorder := httptest.NewRecorder()
+			(&Problem{Title: "Teapot"}).ServeHTTP(recorder, request)
+			if recorder.Code != http.StatusInternalServerError {
+				t.Errorf("status of Problem without Status = %d, want %d", recorder.Code, http.StatusInternalServerError)
+			}
+			if got := recorder.Header().Get("Content-Type"); got != tt.wantType {
+				t.Errorf("Content-Type = %q, want %q", got, tt.wantType)
+			}
+			if got := recorder.Header().Get("X-Content-Type-Options"); got != "nosniff" {
+				t.Errorf("X-Content-Type-Options = %q, want nosniff", got)
+			}
+		})
+	}
+}
+
+func TestProblemError(t *testing.T) {
+	tests := []struct {
+		problem Problem
+		want    string
+	}{
+		{Problem{}, "Internal Server Error"},
+		{Problem{Status: http.StatusNotFound}, "Not Found"},
+		{Problem{Title: "Out of credit", Status: http.StatusForbidden}, "Out of credit"},
+		{Problem{Status: http.StatusNotFound, Detail: "User 123"}, "Not Found: User 123"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := tt.problem.Error(); got != tt.want {
+				t.Errorf("Error() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -43,6 +43,14 @@ type redirect struct {
 	targetURL  string
 }
 
+func (r redirect) problem() *Problem {
+	return NewProblem(r.statusCode)
+}
+
+func (r redirect) StatusCode() int {
+	return r.statusCode
+}
+
 func (r redirect) Error() string {
 	return fmt.Sprintf("%d redirect to %s", r.statusCode, r.targetURL)
 }
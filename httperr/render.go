@@ -0,0 +1,177 @@
+package httperr
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"net/http"
+
+	"github.com/ungerik/go-httpx/contenttype"
+)
+
+// ErrorRenderer is an interface for writing an error response
+// with a status code and message in a specific format.
+type ErrorRenderer interface {
+	RenderError(writer http.ResponseWriter, request *http.Request, statusCode int, message string)
+}
+
+// ErrorRendererFunc is an adapter type that allows ordinary functions to be used as error renderers.
+// It implements the ErrorRenderer interface.
+type ErrorRendererFunc func(writer http.ResponseWriter, request *http.Request, statusCode int, message string)
+
+// RenderError implements the ErrorRenderer interface for ErrorRendererFunc.
+func (f ErrorRendererFunc) RenderError(writer http.ResponseWriter, request *http.Request, statusCode int, message string) {
+	f(writer, request, statusCode, message)
+}
+
+// MediaTypeErrorRenderer associates an ErrorRenderer
+// with the media type of the response it writes.
+type MediaTypeErrorRenderer struct {
+	MediaType string
+	Renderer  ErrorRenderer
+}
+
+// ErrorRenderers are used by RenderError to write error responses
+// in the format that is preferred by the Accept header of the request.
+// The first renderer is used if the request has no Accept header
+// or if none of the media types is acceptable.
+// Use SetErrorRenderer to replace or add renderers.
+//
+// By default, the following media types are rendered:
+//   - text/plain: the message as text like http.Error
+//   - application/json: the status and message as problem details object
+//   - application/problem+json: the status and message as problem details object
+//   - application/xml: the status and message as problem details element
+//   - application/problem+xml: the status and message as problem details element
+//   - text/html: a minimal HTML page showing the status and message
+var ErrorRenderers = []MediaTypeErrorRenderer{
+	{contenttype.PlainText, ErrorRendererFunc(RenderErrorPlaintext)},
+	{contenttype.JSON, ErrorRendererFunc(RenderErrorJSON)},
+	{contenttype.ProblemJSON, ErrorRendererFunc(RenderErrorProblemJSON)},
+	{contenttype.XML, ErrorRendererFunc(RenderErrorXML)},
+	{contenttype.ProblemXML, ErrorRendererFunc(RenderErrorProblemXML)},
+	{contenttype.HTML, ErrorRendererFunc(RenderErrorHTML)},
+}
+
+// SetErrorRenderer replaces the renderer for the media type
+// of contentType in ErrorRenderers or appends it if there is
+// no renderer for the media type yet.
+//
+// Example:
+//
+//	httperr.SetErrorRenderer(contenttype.HTML, httperr.ErrorRendererFunc(renderErrorPage))
+func SetErrorRenderer(contentType string, renderer ErrorRenderer) {
+	mediaType := contenttype.MediaType(contentType)
+	for i := range ErrorRenderers {
+		if contenttype.MediaType(ErrorRenderers[i].MediaType) == mediaType {
+			ErrorRenderers[i].Renderer = renderer
+			return
+		}
+	}
+	ErrorRenderers = append(ErrorRenderers, MediaTypeErrorRenderer{MediaType: contentType, Renderer: renderer})
+}
+
+// RenderError writes an error response with statusCode and message
+// using the renderer from ErrorRenderers that best matches the Accept
+// header of the request.
+// If message is empty, then the standard status text will be used.
+func RenderError(writer http.ResponseWriter, request *http.Request, statusCode int, message string) {
+	if message == "" {
+		message = http.StatusText(statusCode)
+	}
+	if len(ErrorRenderers) == 0 {
+		RenderErrorPlaintext(writer, request, statusCode, message)
+		return
+	}
+	renderer := ErrorRenderers[0].Renderer
+	if request != nil && len(ErrorRenderers) > 1 {
+		offers := make([]string, len(ErrorRenderers))
+		for i, r := range ErrorRenderers {
+			offers[i] = r.MediaType
+		}
+		best := contenttype.Negotiate(request.Header.Get("Accept"), offers...)
+		for _, r := range ErrorRenderers {
+			if r.MediaType == best {
+				renderer = r.Renderer
+				break
+			}
+		}
+		contenttype.AddVary(writer.Header(), "Accept")
+	}
+	renderer.RenderError(writer, request, statusCode, message)
+}
+
+// RenderInternalServerError writes err as 500 Internal Server Error response
+// using RenderError to negotiate the format with the request.
+// If DebugShowInternalErrorsInResponse is true, then the error message
+// will be shown in the response body, else only "Internal Server Error" will be used.
+func RenderInternalServerError(err any, writer http.ResponseWriter, request *http.Request) {
+	message := http.StatusText(http.StatusInternalServerError)
+	if DebugShowInternalErrorsInResponse {
+		message += fmt.Sprintf(DebugShowInternalErrorsInResponseFormat, err)
+	}
+	RenderError(writer, request, http.StatusInternalServerError, message)
+}
+
+// RenderErrorPlaintext writes message as text/plain response like http.Error.
+func RenderErrorPlaintext(writer http.ResponseWriter, _ *http.Request, statusCode int, message string) {
+	http.Error(writer, message, statusCode)
+}
+
+// RenderErrorJSON writes statusCode and message as problem details
+// object with the content type application/json.
+func RenderErrorJSON(writer http.ResponseWriter, _ *http.Request, statusCode int, message string) {
+	writeRenderedError(writer, contenttype.JSON, statusCode, message, json.Marshal)
+}
+
+// RenderErrorProblemJSON writes statusCode and message as
+// application/problem+json response.
+func RenderErrorProblemJSON(writer http.ResponseWriter, _ *http.Request, statusCode int, message string) {
+	writeRenderedError(writer, contenttype.ProblemJSON, statusCode, message, json.Marshal)
+}
+
+// RenderErrorXML writes statusCode and message as problem details
+// element with the content type application/xml.
+func RenderErrorXML(writer http.ResponseWriter, _ *http.Request, statusCode int, message string) {
+	writeRenderedError(writer, contenttype.XML, statusCode, message, marshalXMLWithHeader)
+}
+
+// RenderErrorProblemXML writes statusCode and message as
+// application/problem+xml response.
+func RenderErrorProblemXML(writer http.ResponseWriter, _ *http.Request, statusCode int, message string) {
+	writeRenderedError(writer, contenttype.ProblemXML, statusCode, message, marshalXMLWithHeader)
+}
+
+// RenderErrorHTML writes statusCode and message as minimal HTML page.
+func RenderErrorHTML(writer http.ResponseWriter, _ *http.Request, statusCode int, message string) {
+	title := html.EscapeString(fmt.Sprintf("%d %s", statusCode, http.StatusText(statusCode)))
+	writer.Header().Set("Content-Type", contenttype.HTML)
+	writer.Header().Set("X-Content-Type-Options", "nosniff")
+	writer.WriteHeader(statusCode)
+	fmt.Fprintf(writer, "<!DOCTYPE html>\n<html>\n<head><title>%s</title></head>\n<body>\n<h1>%s</h1>\n<pre>%s</pre>\n</body>\n</html>\n", title, title, html.EscapeString(message)) //#nosec G104
+}
+
+func writeRenderedError(writer http.ResponseWriter, contentType string, statusCode int, message string, marshal func(any) ([]byte, error)) {
+	problem := NewProblem(statusCode)
+	if message != problem.Title {
+		problem.Detail = message
+	}
+	body, err := marshal(problem)
+	if err != nil {
+		http.Error(writer, message, statusCode)
+		return
+	}
+	writer.Header().Set("Content-Type", contentType)
+	writer.Header().Set("X-Content-Type-Options", "nosniff")
+	writer.WriteHeader(statusCode)
+	writer.Write(body) //#nosec G104
+}
+
+func marshalXMLWithHeader(v any) ([]byte, error) {
+	body, err := xml.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
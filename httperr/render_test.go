@@ -0,0 +1,151 @@
+package httperr
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ungerik/go-httpx/contenttype"
+)
+
+func ExampleRenderError() {
+	for _, accept := range []string{"", "application/json", "text/html;q=0.5, application/xml"} {
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		request.Header.Set("Accept", accept)
+		recorder := httptest.NewRecorder()
+
+		Handle(NotFound, recorder, request)
+
+		fmt.Println(recorder.Code, recorder.Header().Get("Content-Type"))
+	}
+
+	// Output:
+	// 404 text/plain; charset=utf-8
+	// 404 application/json; charset=utf-8
+	// 404 application/xml
+}
+
+func TestRenderError(t *testing.T) {
+	tests := []struct {
+		accept   string
+		wantType string
+		wantBody string
+	}{
+		{"", contenttype.PlainText, "Not Found\n"},
+		{"*/*", contenttype.PlainText, "Not Found\n"},
+		{"application/json", contenttype.JSON, `{"title":"Not Found","status":404}`},
+		{"application/problem+json", contenttype.ProblemJSON, `{"title":"Not Found","status":404}`},
+		{"application/*", contenttype.JSON, `{"title":"Not Found","status":404}`},
+		{"application/problem+xml", contenttype.ProblemXML, xml.Header + `<problem xmlns="urn:ietf:rfc:7807"><title>Not Found</title><status>404</status></problem>`},
+		{"text/html, text/plain;q=0.9", contenttype.HTML, ""},
+		{"image/png", contenttype.PlainText, "Not Found\n"},
+		{"text/plain;q=0, application/json;q=0.1", contenttype.JSON, `{"title":"Not Found","status":404}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/", nil)
+			request.Header.Set("Accept", tt.accept)
+			recorder := httptest.NewRecorder()
+			RenderError(recorder, request, http.StatusNotFound, "")
+			if recorder.Code != http.StatusNotFound {
+				t.Errorf("status = %d, want %d", recorder.Code, http.StatusNotFound)
+			}
+			if got := recorder.Header().Get("Content-Type"); got != tt.wantType {
+				t.Errorf("Content-Type = %q, want %q", got, tt.wantType)
+			}
+			if got := recorder.Header().Get("Vary"); got != "Accept" {
+				t.Errorf("Vary = %q, want Accept", got)
+			}
+			if tt.wantBody != "" && recorder.Body.String() != tt.wantBody {
+				t.Errorf("body = %q, want %q", recorder.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestRenderErrorHTMLEscapesMessage(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	RenderErrorHTML(recorder, nil, http.StatusBadRequest, `<script>alert("x")</script>`)
+	body := recorder.Body.String()
+	if strings.Contains(body, "<script>") {
+		t.Errorf("message not escaped: %s", body)
+	}
+	if !strings.Contains(body, "&lt;script&gt;") {
+		t.Errorf("escaped message missing: %s", body)
+	}
+}
+
+func TestRenderErrorWithoutNegotiation(t *testing.T) {
+	defer func(renderers []MediaTypeErrorRenderer) { ErrorRenderers = renderers }(ErrorRenderers)
+
+	tests := []struct {
+		name      string
+		renderers []MediaTypeErrorRenderer
+		request   *http.Request
+		wantType  string
+	}{
+		{"no renderers", nil, httptest.NewRequest(http.MethodGet, "/", nil), contenttype.PlainText},
+		{"nil request", ErrorRenderers, nil, contenttype.PlainText},
+		{"single renderer", []MediaTypeErrorRenderer{{contenttype.JSON, ErrorRendererFunc(RenderErrorJSON)}}, httptest.NewRequest(http.MethodGet, "/", nil), contenttype.JSON},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ErrorRenderers = tt.renderers
+			recorder := httptest.NewRecorder()
+			RenderError(recorder, tt.request, http.StatusConflict, "")
+			if got := recorder.Header().Get("Content-Type"); got != tt.wantType {
+				t.Errorf("Content-Type = %q, want %q", got, tt.wantType)
+			}
+			if got := recorder.Header().Get("Vary"); got != "" {
+				t.Errorf("Vary = %q without negotiation", got)
+			}
+		})
+	}
+}
+
+func TestSetErrorRenderer(t *testing.T) {
+	defer func(renderers []MediaTypeErrorRenderer) { ErrorRenderers = renderers }(append([]MediaTypeErrorRenderer(nil), ErrorRenderers...))
+
+	teapot := ErrorRendererFunc(func(w http.ResponseWriter, r *http.Request, statusCode int, message string) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	count := len(ErrorRenderers)
+	SetErrorRenderer(contenttype.HTML, teapot)
+	if len(ErrorRenderers) != count {
+		t.Errorf("replacing a renderer changed the number of renderers to %d", len(ErrorRenderers))
+	}
+	SetErrorRenderer("text/csv", teapot)
+	if len(ErrorRenderers) != count+1 {
+		t.Errorf("adding a renderer resulted in %d renderers, want %d", len(ErrorRenderers), count+1)
+	}
+
+	for _, accept := range []string{"text/html", "text/csv"} {
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		request.Header.Set("Accept", accept)
+		recorder := httptest.NewRecorder()
+		RenderError(recorder, request, http.StatusNotFound, "")
+		if recorder.Code != http.StatusTeapot {
+			t.Errorf("Accept %s: status = %d, want %d", accept, recorder.Code, http.StatusTeapot)
+		}
+	}
+}
+
+func TestRenderInternalServerError(t *testing.T) {
+	defer func(debug bool) { DebugShowInternalErrorsInResponse = debug }(DebugShowInternalErrorsInResponse)
+
+	for _, debug := range []bool{false, true} {
+		DebugShowInternalErrorsInResponse = debug
+		recorder := httptest.NewRecorder()
+		RenderInternalServerError(errors.New("secret"), recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+		if recorder.Code != http.StatusInternalServerError {
+			t.Errorf("status = %d, want %d", recorder.Code, http.StatusInternalServerError)
+		}
+		if got := strings.Contains(recorder.Body.String(), "secret"); got != debug {
+			t.Errorf("DebugShowInternalErrorsInResponse = %t: body %q contains error message: %t", debug, recorder.Body.String(), got)
+		}
+	}
+}
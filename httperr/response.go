@@ -37,16 +37,55 @@ func New(statusCode int, statusText ...string) Response {
 	}
 }
 
-// Errorf creates a Response error with a formatted message using fmt.Sprintf.
+// Errorf creates a Response error with a formatted message using fmt.Errorf.
 // This is similar to New but allows for formatted error messages.
+// Errors formatted with the %w verb are wrapped and can be
+// found with errors.Is and errors.As, but note that their message
+// is part of the response body. Use WithStatus to respond with
+// a public message that does not expose the wrapped error.
 //
 // Example:
 //
 //	err := httperr.Errorf(http.StatusBadRequest, "User %s not found", username)
+//	err := httperr.Errorf(http.StatusBadRequest, "Invalid input: %w", err)
 func Errorf(statusCode int, format string, a ...any) Response {
+	err := fmt.Errorf(format, a...)
+	switch err.(type) {
+	case interface{ Unwrap() error }, interface{ Unwrap() []error }:
+		return statusCodeAndCause{
+			statusCode: statusCode,
+			statusText: err.Error(),
+			cause:      err,
+		}
+	}
 	return statusCodeAndText{
 		statusCode: statusCode,
-		statusText: fmt.Sprintf(format, a...),
+		statusText: err.Error(),
+	}
+}
+
+// WithStatus wraps err as a Response error with the given HTTP status code.
+// The response body only contains the publicMessage strings joined with newlines,
+// or the standard HTTP status text if no publicMessage is provided,
+// so internal details of err are not exposed to the client.
+// The Error method returns the public message followed by the message of err
+// and Unwrap returns err for errors.Is, errors.As, and logging.
+// If err is nil, then nil is returned.
+//
+// Example:
+//
+//	user, err := db.GetUser(id)
+//	if err != nil {
+//	    return httperr.WithStatus(err, http.StatusBadGateway, "User database unavailable")
+//	}
+func WithStatus(err error, statusCode int, publicMessage ...string) Response {
+	if err == nil {
+		return nil
+	}
+	return statusCodeAndCause{
+		statusCode: statusCode,
+		statusText: strings.Join(publicMessage, "\n"),
+		cause:      err,
 	}
 }
 
@@ -60,6 +99,14 @@ func NewFromResponse(resonse *http.Response) Response {
 	}
 }
 
+func (e statusCodeAndText) problem() *Problem {
+	return newProblemWithText(e.statusCode, e.statusText)
+}
+
+func (e statusCodeAndText) StatusCode() int {
+	return e.statusCode
+}
+
 func (e statusCodeAndText) Error() string {
 	if e.statusText == "" {
 		return http.StatusText(e.statusCode)
@@ -67,6 +114,49 @@ func (e statusCodeAndText) Error() string {
 	return e.statusText
 }
 
+// ServeHTTP writes the status code and text using RenderError
+// in the format negotiated with the Accept header of the request.
 func (e statusCodeAndText) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
-	http.Error(writer, e.Error(), e.statusCode)
+	RenderError(writer, request, e.statusCode, e.Error())
+}
+
+// statusCodeAndCause is a statusCodeAndText
+// that wraps the error that caused it
+type statusCodeAndCause struct {
+	statusCode int
+	statusText string
+	cause      error
+}
+
+func (e statusCodeAndCause) problem() *Problem {
+	return newProblemWithText(e.statusCode, e.statusText)
+}
+
+func (e statusCodeAndCause) StatusCode() int {
+	return e.statusCode
+}
+
+func (e statusCodeAndCause) publicText() string {
+	if e.statusText == "" {
+		return http.StatusText(e.statusCode)
+	}
+	return e.statusText
+}
+
+func (e statusCodeAndCause) Error() string {
+	text := e.publicText()
+	if cause := e.cause.Error(); cause != text {
+		return text + ": " + cause
+	}
+	return text
+}
+
+func (e statusCodeAndCause) Unwrap() error {
+	return e.cause
+}
+
+// ServeHTTP writes the status code and only the public text using
+// RenderError in the format negotiated with the Accept header of the request.
+func (e statusCodeAndCause) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	RenderError(writer, request, e.statusCode, e.publicText())
 }
@@ -0,0 +1,114 @@
+package httperr
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func ExampleWithStatus() {
+	err := WithStatus(os.ErrPermission, http.StatusForbidden, "Access to report denied")
+
+	recorder := httptest.NewRecorder()
+	Handle(err, recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	fmt.Println(errors.Is(err, os.ErrPermission))
+	fmt.Println(err)
+	fmt.Print(recorder.Code, " ", recorder.Body.String())
+
+	// Output:
+	// true
+	// Access to report denied: permission denied
+	// 403 Access to report denied
+}
+
+func ExampleErrorf() {
+	err := Errorf(http.StatusBadRequest, "invalid input: %w", os.ErrInvalid)
+
+	fmt.Println(errors.Is(err, os.ErrInvalid), StatusCode(err))
+	fmt.Println(err)
+
+	// Output:
+	// true 400
+	// invalid input: invalid argument
+}
+
+func TestWithStatus(t *testing.T) {
+	cause := fmt.Errorf("query users: %w", os.ErrDeadlineExceeded)
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantBody   string
+		wantError  string
+	}{
+		{"public message", WithStatus(cause, http.StatusServiceUnavailable, "Try again later"), http.StatusServiceUnavailable, "Try again later", "Try again later: query users: i/o timeout"},
+		{"no public message", WithStatus(cause, http.StatusBadGateway), http.StatusBadGateway, "Bad Gateway", "Bad Gateway: query users: i/o timeout"},
+		{"multiple public messages", WithStatus(cause, http.StatusBadRequest, "line 1", "line 2"), http.StatusBadRequest, "line 1\nline 2", "line 1\nline 2: query users: i/o timeout"},
+		{"public message equals cause", WithStatus(os.ErrDeadlineExceeded, http.StatusGatewayTimeout, "i/o timeout"), http.StatusGatewayTimeout, "i/o timeout", "i/o timeout"},
+		{"wrapped", fmt.Errorf("handler: %w", WithStatus(cause, http.StatusConflict, "Conflict")), http.StatusConflict, "Conflict", "handler: Conflict: query users: i/o timeout"},
+		{"outer status wins", WithStatus(WithStatus(cause, http.StatusConflict), http.StatusGone), http.StatusGone, "Gone", "Gone: Conflict: query users: i/o timeout"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !errors.Is(tt.err, os.ErrDeadlineExceeded) {
+				t.Error("cause is not wrapped")
+			}
+			if got := StatusCode(tt.err); got != tt.wantStatus {
+				t.Errorf("StatusCode() = %d, want %d", got, tt.wantStatus)
+			}
+			if got := tt.err.Error(); got != tt.wantError {
+				t.Errorf("Error() = %q, want %q", got, tt.wantError)
+			}
+			recorder := httptest.NewRecorder()
+			Handle(tt.err, recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+			if got := strings.TrimSuffix(recorder.Body.String(), "\n"); got != tt.wantBody {
+				t.Errorf("body = %q, want %q", got, tt.wantBody)
+			}
+		})
+	}
+	if WithStatus(nil, http.StatusBadRequest) != nil {
+		t.Error("WithStatus(nil) != nil")
+	}
+}
+
+func TestErrorf(t *testing.T) {
+	errA := errors.New("a")
+	errB := errors.New("b")
+	tests := []struct {
+		name      string
+		err       error
+		wantError string
+		wantIs    []error
+	}{
+		{"no wrapping", Errorf(http.StatusBadRequest, "invalid %s", "name"), "invalid name", nil},
+		{"%v does not wrap", Errorf(http.StatusBadRequest, "invalid: %v", errA), "invalid: a", nil},
+		{"%w wraps", Errorf(http.StatusBadRequest, "invalid: %w", errA), "invalid: a", []error{errA}},
+		{"multiple %w", Errorf(http.StatusBadRequest, "invalid: %w, %w", errA, errB), "invalid: a, b", []error{errA, errB}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.Error(); got != tt.wantError {
+				t.Errorf("Error() = %q, want %q", got, tt.wantError)
+			}
+			if tt.wantIs == nil && errors.Unwrap(tt.err) != nil {
+				t.Errorf("Errorf() wraps %v", errors.Unwrap(tt.err))
+			}
+			for _, target := range tt.wantIs {
+				if !errors.Is(tt.err, target) {
+					t.Errorf("errors.Is(%v) = false", target)
+				}
+			}
+			if StatusCode(tt.err) != http.StatusBadRequest {
+				t.Errorf("StatusCode() = %d, want %d", StatusCode(tt.err), http.StatusBadRequest)
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package httperr
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"syscall"
+)
+
+// BuiltinSentinelPriority is the priority of the handlers
+// registered by default in Sentinels.
+// Register handlers with a higher priority to override them.
+const BuiltinSentinelPriority = -1000
+
+// ErrorMatcher reports whether err matches a condition
+// like wrapping a sentinel error or an error of a certain type.
+type ErrorMatcher func(err error) bool
+
+// MatchIs returns an ErrorMatcher that matches
+// errors where errors.Is(err, target) is true.
+func MatchIs(target error) ErrorMatcher {
+	return func(err error) bool {
+		return errors.Is(err, target)
+	}
+}
+
+// MatchAs returns an ErrorMatcher that matches errors
+// where errors.As finds an error of type T in the chain.
+//
+// Example:
+//
+//	httperr.Sentinels.Register(0, httperr.MatchAs[*json.SyntaxError](), httperr.BadRequest)
+func MatchAs[T error]() ErrorMatcher {
+	return func(err error) bool {
+		var target T
+		return errors.As(err, &target)
+	}
+}
+
+// SentinelRegistry is an ordered collection of error matchers
+// and the http.Handler that writes the response for a matching error.
+//
+// Handlers with a higher priority are matched first,
+// handlers with the same priority in the order of their registration.
+// This makes the chosen handler deterministic for errors
+// that wrap multiple sentinels, for example via errors.Join.
+//
+// A SentinelRegistry is safe for concurrent use.
+// The zero value is an empty registry ready to use.
+type SentinelRegistry struct {
+	mtx     sync.RWMutex
+	entries []sentinelEntry
+}
+
+type sentinelEntry struct {
+	priority int
+	match    ErrorMatcher
+	handler  http.Handler
+}
+
+// Register adds a handler for errors matched by match
+// with the passed priority.
+//
+// Example:
+//
+//	httperr.Sentinels.Register(10, httperr.MatchIs(myapp.ErrRateLimited), httperr.New(http.StatusTooManyRequests))
+func (r *SentinelRegistry) Register(priority int, match ErrorMatcher, handler http.Handler) {
+	if match == nil || handler == nil {
+		panic("httperr: nil ErrorMatcher or http.Handler")
+	}
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	// Insert after all entries with the same or a higher priority
+	i := sort.Search(len(r.entries), func(i int) bool { return r.entries[i].priority < priority })
+	r.entries = append(r.entries, sentinelEntry{})
+	copy(r.entries[i+1:], r.entries[i:])
+	r.entries[i] = sentinelEntry{priority: priority, match: match, handler: handler}
+}
+
+// RegisterSentinel adds a handler for errors that wrap sentinel
+// with the passed priority.
+func (r *SentinelRegistry) RegisterSentinel(priority int, sentinel error, handler http.Handler) {
+	r.Register(priority, MatchIs(sentinel), handler)
+}
+
+// Handler returns the handler of the first registered
+// matcher that matches err or false if none matches.
+func (r *SentinelRegistry) Handler(err error) (handler http.Handler, ok bool) {
+	if err == nil {
+		return nil, false
+	}
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	for _, entry := range r.entries {
+		if entry.match(err) {
+			return entry.handler, true
+		}
+	}
+	return nil, false
+}
+
+// Clear removes all registered handlers.
+// Use it to remove the handlers registered by default in Sentinels.
+func (r *SentinelRegistry) Clear() {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	r.entries = nil
+}
+
+// Len returns the number of registered handlers.
+func (r *SentinelRegistry) Len() int {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	return len(r.entries)
+}
+
+// sentinelHandlersHandler returns the handler from SentinelHandlers
+// for the first sentinel wrapped by err.
+// The sentinels are checked sorted by their error message
+// to make the result independent of the map iteration order.
+func sentinelHandlersHandler(err error) (handler http.Handler, ok bool) {
+	sentinels := make([]error, 0, len(SentinelHandlers))
+	for sentinel := range SentinelHandlers {
+		if errors.Is(err, sentinel) {
+			sentinels = append(sentinels, sentinel)
+		}
+	}
+	if len(sentinels) == 0 {
+		return nil, false
+	}
+	sort.Slice(sentinels, func(i, j int) bool { return sentinels[i].Error() < sentinels[j].Error() })
+	return SentinelHandlers[sentinels[0]], true
+}
+
+// newBuiltinSentinels returns a SentinelRegistry with
+// handlers for context, network and request body size errors.
+func newBuiltinSentinels() *SentinelRegistry {
+	r := new(SentinelRegistry)
+	r.Register(BuiltinSentinelPriority, MatchAs[*http.MaxBytesError](), New(http.StatusRequestEntityTooLarge))
+	r.RegisterSentinel(BuiltinSentinelPriority, context.Canceled, New(StatusClientClosedRequest, "Client Closed Request"))
+	r.Register(BuiltinSentinelPriority, isUpstreamTimeout, New(http.StatusGatewayTimeout))
+	r.RegisterSentinel(BuiltinSentinelPriority, context.DeadlineExceeded, New(http.StatusServiceUnavailable))
+	r.Register(BuiltinSentinelPriority, isUpstreamError, New(http.StatusBadGateway))
+	return r
+}
+
+// isUpstreamTimeout matches timeouts of outgoing HTTP requests
+// of a http.Client and of dials of network connections.
+// Timeouts reading or writing the connection of the client,
+// like a slow upload exceeding the ReadTimeout of the server,
+// are not matched.
+func isUpstreamTimeout(err error) bool {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) && isRequestOp(urlErr.Op) && urlErr.Timeout() {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial" && opErr.Timeout()
+}
+
+// isUpstreamError matches failed outgoing HTTP requests
+// of a http.Client and failed dials of network connections.
+// Errors from parsing URLs or reading and writing
+// the connection of the client are not matched.
+func isUpstreamError(err error) bool {
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) && isRequestOp(urlErr.Op) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// isRequestOp returns true if op is the Op of an url.Error
+// returned by http.Client, which is the request method
+// with an upper case first letter like "Get"
+func isRequestOp(op string) bool {
+	switch op {
+	case "Get", "Head", "Post", "Put", "Patch", "Delete", "Connect", "Options", "Trace":
+		return true
+	}
+	return false
+}
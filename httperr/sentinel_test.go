@@ -0,0 +1,208 @@
+package httperr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"syscall"
+	"testing"
+)
+
+func ExampleSentinelRegistry() {
+	var registry SentinelRegistry
+	registry.Register(0, MatchAs[*fs.PathError](), New(http.StatusForbidden))
+	registry.RegisterSentinel(10, fs.ErrNotExist, New(http.StatusGone))
+
+	err := fmt.Errorf("loading config: %w", &fs.PathError{Op: "open", Path: "config.json", Err: fs.ErrNotExist})
+	handler, _ := registry.Handler(err)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	fmt.Println(recorder.Code)
+
+	_, ok := registry.Handler(errors.New("other"))
+	fmt.Println(ok)
+
+	// Output:
+	// 410
+	// false
+}
+
+func ExampleSentinels() {
+	errs := []error{
+		context.Canceled,
+		fmt.Errorf("query: %w", context.DeadlineExceeded),
+		&url.Error{Op: "Get", URL: "http://upstream", Err: syscall.ECONNREFUSED},
+		&http.MaxBytesError{Limit: 1024},
+	}
+	for _, err := range errs {
+		recorder := httptest.NewRecorder()
+		Handle(err, recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+		fmt.Println(recorder.Code, ShouldLog(err))
+	}
+
+	// Output:
+	// 499 false
+	// 503 true
+	// 502 true
+	// 413 true
+}
+
+func TestSentinelsNetworkErrors(t *testing.T) {
+	_, parseErr := url.Parse("http://[::1")
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"url parse error", parseErr, http.StatusInternalServerError},
+		{"client request error", &url.Error{Op: "Get", URL: "http://upstream", Err: errors.New("EOF")}, http.StatusBadGateway},
+		{"client request timeout", &url.Error{Op: "Post", URL: "http://upstream", Err: timeoutError{}}, http.StatusGatewayTimeout},
+		{"dial error", &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("no route to host")}, http.StatusBadGateway},
+		{"connection refused", fmt.Errorf("connecting: %w", syscall.ECONNREFUSED), http.StatusBadGateway},
+		{"client connection write error", &net.OpError{Op: "write", Net: "tcp", Err: syscall.EPIPE}, http.StatusInternalServerError},
+		{"client connection read error", &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}, http.StatusInternalServerError},
+		{"client connection read timeout", &net.OpError{Op: "read", Net: "tcp", Err: timeoutError{}}, http.StatusInternalServerError},
+		{"client connection deadline", fmt.Errorf("reading body: %w", os.ErrDeadlineExceeded), http.StatusInternalServerError},
+		{"dial timeout", &net.OpError{Op: "dial", Net: "tcp", Err: timeoutError{}}, http.StatusGatewayTimeout},
+		{"canceled", context.Canceled, StatusClientClosedRequest},
+		{"body too large", fmt.Errorf("decoding: %w", &http.MaxBytesError{Limit: 10}), http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StatusCode(tt.err); got != tt.want {
+				t.Errorf("StatusCode() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSentinelHandlersPrecedeSentinels(t *testing.T) {
+	SentinelHandlers[context.Canceled] = New(http.StatusTeapot)
+	defer delete(SentinelHandlers, context.Canceled)
+
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"SentinelHandlers entry", fmt.Errorf("query: %w", context.Canceled), http.StatusTeapot},
+		{"built-in Sentinels entry", context.DeadlineExceeded, http.StatusServiceUnavailable},
+		{"default SentinelHandlers entry", &fs.PathError{Op: "open", Path: "x", Err: os.ErrNotExist}, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			Handle(tt.err, recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+			if recorder.Code != tt.want {
+				t.Errorf("Handle() wrote %d, want %d", recorder.Code, tt.want)
+			}
+			if got := StatusCode(tt.err); got != tt.want {
+				t.Errorf("StatusCode() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestSentinelRegistryPriority(t *testing.T) {
+	errA := errors.New("a")
+	errB := errors.New("b")
+	errC := errors.New("c")
+	var registry SentinelRegistry
+	registry.RegisterSentinel(0, errA, New(http.StatusBadRequest))
+	registry.RegisterSentinel(10, errB, New(http.StatusConflict))
+	registry.RegisterSentinel(0, errB, New(http.StatusGone))
+	registry.RegisterSentinel(-10, errC, New(http.StatusTeapot))
+	registry.Register(0, MatchAs[*fs.PathError](), New(http.StatusNotFound))
+
+	tests := []struct {
+		name   string
+		err    error
+		want   int
+		wantOK bool
+	}{
+		{"nil", nil, 0, false},
+		{"no match", errors.New("other"), 0, false},
+		{"single match", errA, http.StatusBadRequest, true},
+		{"wrapped", fmt.Errorf("x: %w", errC), http.StatusTeapot, true},
+		{"higher priority wins", errors.Join(errA, errB), http.StatusConflict, true},
+		{"higher priority wins regardless of join order", errors.Join(errB, errA), http.StatusConflict, true},
+		{"same priority in registration order", errors.Join(errC, &fs.PathError{Err: errA}), http.StatusBadRequest, true},
+		{"lower priority only if nothing else matches", errors.Join(errC, &fs.PathError{}), http.StatusNotFound, true},
+		{"MatchAs", &fs.PathError{Op: "open", Err: os.ErrPermission}, http.StatusNotFound, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, ok := registry.Handler(tt.err)
+			if ok != tt.wantOK {
+				t.Fatalf("Handler() ok = %t, want %t", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+			if recorder.Code != tt.want {
+				t.Errorf("Handler() status = %d, want %d", recorder.Code, tt.want)
+			}
+		})
+	}
+
+	if registry.Len() != 5 {
+		t.Errorf("Len() = %d, want 5", registry.Len())
+	}
+	registry.Clear()
+	if _, ok := registry.Handler(errA); ok || registry.Len() != 0 {
+		t.Error("Clear() did not remove all handlers")
+	}
+}
+
+func TestSentinelRegistryRegisterNil(t *testing.T) {
+	tests := []struct {
+		name    string
+		match   ErrorMatcher
+		handler http.Handler
+	}{
+		{"nil matcher", nil, NotFound},
+		{"nil handler", MatchIs(os.ErrNotExist), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("Register() did not panic")
+				}
+			}()
+			var registry SentinelRegistry
+			registry.Register(0, tt.match, tt.handler)
+		})
+	}
+}
+
+func TestSentinelHandlersDeterministic(t *testing.T) {
+	errZ := errors.New("z sentinel")
+	errA := errors.New("a sentinel")
+	SentinelHandlers[errZ] = New(http.StatusConflict)
+	SentinelHandlers[errA] = New(http.StatusGone)
+	defer delete(SentinelHandlers, errZ)
+	defer delete(SentinelHandlers, errA)
+
+	for i := 0; i < 20; i++ {
+		recorder := httptest.NewRecorder()
+		Handle(errors.Join(errZ, errA), recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+		if recorder.Code != http.StatusGone {
+			t.Fatalf("Handle() wrote %d, want %d of the sentinel with the smallest message", recorder.Code, http.StatusGone)
+		}
+	}
+}
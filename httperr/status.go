@@ -0,0 +1,73 @@
+package httperr
+
+import (
+	"errors"
+	"net/http"
+)
+
+// StatusCoder is implemented by errors that know
+// the HTTP status code of the response they cause.
+// All Response implementations of this package implement it.
+//
+// DefaultHandlerImpl writes the status code of errors implementing
+// StatusCoder but not http.Handler together with the standard status text.
+type StatusCoder interface {
+	StatusCode() int
+}
+
+// StatusCode returns the HTTP status code that DefaultHandlerImpl
+// would write for err without writing a response.
+// It walks the error chain in the same order as DefaultHandlerImpl:
+// wrapped http.Handler, SentinelHandlers, Sentinels, StatusCoder,
+// and finally 500 Internal Server Error.
+// Handlers are never called, so the status code of a http.Handler
+// that does not implement StatusCoder is reported as 500 Internal Server Error.
+// A nil error results in zero.
+func StatusCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var handler http.Handler
+	if errors.As(err, &handler) {
+		return handlerStatusCode(handler)
+	}
+	if handler, ok := sentinelHandlersHandler(err); ok {
+		return handlerStatusCode(handler)
+	}
+	if handler, ok := Sentinels.Handler(err); ok {
+		return handlerStatusCode(handler)
+	}
+	var coder StatusCoder
+	if errors.As(err, &coder) {
+		return coder.StatusCode()
+	}
+	return http.StatusInternalServerError
+}
+
+// IsClientError returns true if StatusCode(err) is a 4xx client error.
+func IsClientError(err error) bool {
+	code := StatusCode(err)
+	return code >= 400 && code < 500
+}
+
+// IsServerError returns true if StatusCode(err) is a 5xx server error.
+func IsServerError(err error) bool {
+	code := StatusCode(err)
+	return code >= 500 && code < 600
+}
+
+// IsRedirect returns true if StatusCode(err) is a 3xx redirect.
+func IsRedirect(err error) bool {
+	code := StatusCode(err)
+	return code >= 300 && code < 400
+}
+
+// handlerStatusCode returns the status code of handler
+// if it implements StatusCoder, else 500 Internal Server Error.
+// The handler is never called.
+func handlerStatusCode(handler http.Handler) int {
+	if coder, ok := handler.(StatusCoder); ok {
+		return coder.StatusCode()
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,70 @@
+package httperr
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"testing"
+)
+
+func ExampleStatusCode() {
+	fmt.Println(StatusCode(fmt.Errorf("wrapped: %w", Forbidden)))
+	fmt.Println(StatusCode(os.ErrNotExist))
+	fmt.Println(StatusCode(TemporaryRedirect("/login")))
+	fmt.Println(StatusCode(errors.New("unknown")))
+	fmt.Println(StatusCode(nil))
+	fmt.Println(IsClientError(BadRequest), IsServerError(BadRequest), IsRedirect(Redirect(http.StatusFound, "/")))
+
+	// Output:
+	// 403
+	// 404
+	// 307
+	// 500
+	// 0
+	// true false true
+}
+
+func TestStatusCode(t *testing.T) {
+	called := false
+	handlerErr := handlerError{http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusTeapot)
+	})}
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"nil", nil, 0},
+		{"plain error", errors.New("bug"), http.StatusInternalServerError},
+		{"Response", NotFound, http.StatusNotFound},
+		{"wrapped Response", fmt.Errorf("loading: %w", Forbidden), http.StatusForbidden},
+		{"WithStatus", WithStatus(errors.New("db"), http.StatusBadGateway), http.StatusBadGateway},
+		{"WithHeader", WithHeader(New(http.StatusTooManyRequests), "Retry-After", "10"), http.StatusTooManyRequests},
+		{"StatusCoder", quotaError{}, http.StatusTooManyRequests},
+		{"SentinelHandlers", fmt.Errorf("open: %w", os.ErrNotExist), http.StatusNotFound},
+		{"handler without StatusCoder", handlerErr, http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StatusCode(tt.err); got != tt.want {
+				t.Errorf("StatusCode() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+	if called {
+		t.Error("StatusCode called the http.Handler of an error")
+	}
+}
+
+type quotaError struct{}
+
+func (quotaError) Error() string   { return "quota exceeded" }
+func (quotaError) StatusCode() int { return http.StatusTooManyRequests }
+
+type handlerError struct {
+	http.Handler
+}
+
+func (handlerError) Error() string { return "handler error" }
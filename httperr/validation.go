@@ -0,0 +1,149 @@
+package httperr
+
+import (
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// FieldError describes why the value of a single field
+// of a request payload failed validation.
+type FieldError struct {
+	// Field is the path of the field like "email" or "items[2].name"
+	Field string `json:"field,omitempty" xml:"field,omitempty"`
+	// Code is a machine readable error code like "required"
+	Code string `json:"code,omitempty" xml:"code,omitempty"`
+	// Message is a human readable error message
+	Message string `json:"message" xml:"message"`
+	// Value is the rejected value, omitted if nil
+	Value any `json:"rejectedValue,omitempty" xml:"rejectedValue,omitempty"`
+	// Err is an optional error that caused the validation error
+	Err error `json:"-" xml:"-"`
+}
+
+// NewFieldError returns a FieldError for field with code, message and the rejected value.
+func NewFieldError(field, code, message string, value any) *FieldError {
+	return &FieldError{
+		Field:   field,
+		Code:    code,
+		Message: message,
+		Value:   value,
+	}
+}
+
+func (e *FieldError) Error() string {
+	if e.Field == "" {
+		return e.Message
+	}
+	return e.Field + ": " + e.Message
+}
+
+// Unwrap returns Err
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationErrors is a Response error that collects all validation
+// problems of a request payload so they can be returned at once.
+// It is rendered as problem details document with the status code
+// ValidationErrorStatusCode and the field errors as "errors" member.
+//
+// Example:
+//
+//	var errs httperr.ValidationErrors
+//	if user.Name == "" {
+//	    errs.Add("name", "required", "Name is required", nil)
+//	}
+//	if !strings.Contains(user.Email, "@") {
+//	    errs.Add("email", "format", "Invalid email address", user.Email)
+//	}
+//	if err := errs.Err(); err != nil {
+//	    return nil, err
+//	}
+type ValidationErrors []*FieldError
+
+// Add appends a FieldError for field with code, message and the rejected value.
+func (v *ValidationErrors) Add(field, code, message string, value any) {
+	*v = append(*v, NewFieldError(field, code, message, value))
+}
+
+// Append appends errs like errors.Join.
+// Nil errors are ignored, wrapped FieldError and
+// ValidationErrors are flattened, and all other errors
+// are appended as FieldError with their message
+// that unwraps to the original error.
+func (v *ValidationErrors) Append(errs ...error) {
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		var validationErrs ValidationErrors
+		if errors.As(err, &validationErrs) {
+			*v = append(*v, validationErrs...)
+			continue
+		}
+		var fieldErr *FieldError
+		if errors.As(err, &fieldErr) {
+			*v = append(*v, fieldErr)
+			continue
+		}
+		*v = append(*v, &FieldError{Message: err.Error(), Err: err})
+	}
+}
+
+// Err returns v as error or nil if v is empty.
+// Use it to return ValidationErrors without
+// creating a non-nil error from an empty collection.
+func (v ValidationErrors) Err() error {
+	if len(v) == 0 {
+		return nil
+	}
+	return v
+}
+
+func (v ValidationErrors) Error() string {
+	messages := make([]string, len(v))
+	for i, e := range v {
+		messages[i] = e.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+// Unwrap returns the field errors so they
+// can be found with errors.Is and errors.As.
+func (v ValidationErrors) Unwrap() []error {
+	errs := make([]error, len(v))
+	for i, e := range v {
+		errs[i] = e
+	}
+	return errs
+}
+
+// StatusCode returns ValidationErrorStatusCode.
+func (v ValidationErrors) StatusCode() int {
+	return ValidationErrorStatusCode
+}
+
+func (v ValidationErrors) problem() *Problem {
+	problem := NewProblem(v.StatusCode())
+	problem.Extensions = map[string]any{"errors": fieldErrorList(v)}
+	return problem
+}
+
+// ServeHTTP writes the validation errors as problem details document.
+func (v ValidationErrors) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	v.problem().ServeHTTP(writer, request)
+}
+
+// fieldErrorList marshals as JSON array or
+// as XML element with an <i> element per field error
+// like arrays in RFC 9457 problem details XML
+type fieldErrorList []*FieldError
+
+func (l fieldErrorList) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	items := struct {
+		Items []*FieldError `xml:"i"`
+	}{l}
+	return enc.EncodeElement(items, start)
+}
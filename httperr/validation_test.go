@@ -0,0 +1,133 @@
+package httperr
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ungerik/go-httpx/contenttype"
+)
+
+func ExampleValidationErrors() {
+	var errs ValidationErrors
+	errs.Add("name", "required", "Name is required", nil)
+	errs.Append(NewFieldError("age", "min", "Must be at least 18", 12))
+	err := fmt.Errorf("creating user: %w", errs.Err())
+
+	recorder := httptest.NewRecorder()
+	Handle(err, recorder, httptest.NewRequest(http.MethodPost, "/users", nil))
+	fmt.Println(recorder.Code, recorder.Header().Get("Content-Type"))
+	fmt.Println(recorder.Body.String())
+
+	var fieldErr *FieldError
+	fmt.Println(errors.As(err, &fieldErr), fieldErr.Field)
+
+	// Output:
+	// 422 application/problem+json
+	// {
+	//   "title": "Unprocessable Entity",
+	//   "status": 422,
+	//   "errors": [
+	//     {
+	//       "field": "name",
+	//       "code": "required",
+	//       "message": "Name is required"
+	//     },
+	//     {
+	//       "field": "age",
+	//       "code": "min",
+	//       "message": "Must be at least 18",
+	//       "rejectedValue": 12
+	//     }
+	//   ]
+	// }
+	// true name
+}
+
+func TestValidationErrorsAppend(t *testing.T) {
+	plain := errors.New("plain")
+	tests := []struct {
+		name       string
+		errs       []error
+		wantFields []string
+		wantError  string
+	}{
+		{"nothing", nil, nil, ""},
+		{"nil errors", []error{nil, nil}, nil, ""},
+		{"field error", []error{NewFieldError("a", "required", "missing", nil)}, []string{"a"}, "a: missing"},
+		{"wrapped field error", []error{fmt.Errorf("x: %w", NewFieldError("a", "", "missing", nil))}, []string{"a"}, "a: missing"},
+		{"nested ValidationErrors", []error{ValidationErrors{NewFieldError("a", "", "m1", nil), NewFieldError("b", "", "m2", nil)}}, []string{"a", "b"}, "a: m1\nb: m2"},
+		{"plain error", []error{plain}, []string{""}, "plain"},
+		{"mixed", []error{nil, plain, NewFieldError("c", "", "m3", nil)}, []string{"", "c"}, "plain\nc: m3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var errs ValidationErrors
+			errs.Append(tt.errs...)
+			var fields []string
+			for _, e := range errs {
+				fields = append(fields, e.Field)
+			}
+			if !reflect.DeepEqual(fields, tt.wantFields) {
+				t.Errorf("fields = %q, want %q", fields, tt.wantFields)
+			}
+			if err := errs.Err(); (err == nil) != (tt.wantError == "") {
+				t.Fatalf("Err() = %v", err)
+			}
+			if errs.Error() != tt.wantError {
+				t.Errorf("Error() = %q, want %q", errs.Error(), tt.wantError)
+			}
+		})
+	}
+	var errs ValidationErrors
+	errs.Append(plain)
+	if !errors.Is(errs, plain) {
+		t.Error("appended plain error is not found by errors.Is")
+	}
+}
+
+func TestValidationErrorsServeHTTP(t *testing.T) {
+	errs := ValidationErrors{NewFieldError("items[0].name", "required", "Name is required", nil)}
+	tests := []struct {
+		accept   string
+		wantType string
+		wantBody string
+	}{
+		{"", contenttype.ProblemJSON, `"field": "items[0].name"`},
+		{"application/xml", contenttype.ProblemXML, "<errors><i><field>items[0].name</field><code>required</code><message>Name is required</message></i></errors>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.wantType, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPost, "/", nil)
+			request.Header.Set("Accept", tt.accept)
+			recorder := httptest.NewRecorder()
+			Handle(fmt.Errorf("validating: %w", errs), recorder, request)
+			if recorder.Code != ValidationErrorStatusCode {
+				t.Errorf("status = %d, want %d", recorder.Code, ValidationErrorStatusCode)
+			}
+			if got := recorder.Header().Get("Content-Type"); got != tt.wantType {
+				t.Errorf("Content-Type = %q, want %q", got, tt.wantType)
+			}
+			if body := strings.Join(strings.Fields(recorder.Body.String()), ""); !strings.Contains(body, strings.Join(strings.Fields(tt.wantBody), "")) {
+				t.Errorf("body %s does not contain %s", recorder.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestValidationErrorStatusCode(t *testing.T) {
+	defer func(statusCode int) { ValidationErrorStatusCode = statusCode }(ValidationErrorStatusCode)
+	ValidationErrorStatusCode = http.StatusBadRequest
+
+	errs := ValidationErrors{NewFieldError("a", "", "m", nil)}
+	if got := StatusCode(errs); got != http.StatusBadRequest {
+		t.Errorf("StatusCode() = %d, want %d", got, http.StatusBadRequest)
+	}
+	if got := AsProblem(errs).Title; got != "Bad Request" {
+		t.Errorf("problem title = %q, want %q", got, "Bad Request")
+	}
+}
@@ -1,6 +1,7 @@
 package httperr
 
 import (
+	"context"
 	"errors"
 	"fmt"
 )
@@ -20,12 +21,13 @@ func DontLog(err error) error {
 
 // ShouldLog checks if the passed error
 // has been wrapped with DontLog.
-// A nil error results in false.
+// A nil error or an error wrapping context.Canceled
+// because the client canceled the request results in false.
 //
 //	httperr.ShouldLog(httperr.BadRequest) == true
 //	httperr.ShouldLog(httperr.DontLog(httperr.BadRequest)) == false
 func ShouldLog(err error) bool {
-	if err == nil {
+	if err == nil || errors.Is(err, context.Canceled) {
 		return false
 	}
 	var dontLog errDontLog
@@ -4,6 +4,7 @@
 // The package consists of several sub-packages:
 //   - httperr: HTTP error handling and error-to-response conversion
 //   - respond: Simplified response writing for JSON, XML, HTML, and plain text
+//   - compress: Response compression middleware
 //   - contenttype: Constants for common MIME content types
 //   - calling: Function calling utilities with string arguments
 package httpx
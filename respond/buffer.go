@@ -0,0 +1,207 @@
+package respond
+
+import (
+	"bytes"
+	"net/http"
+)
+
+// BufferedResponseWriter is a http.ResponseWriter that buffers the status code,
+// headers, and body of a response until it is committed,
+// so that a partially written response can be discarded
+// and replaced with a clean error response.
+//
+// The response is committed by Commit, Flush, or when the buffered body
+// would exceed the buffer size. Headers set after the response
+// was committed are set on the wrapped http.ResponseWriter.
+//
+// The handlers of this package use a BufferedResponseWriter if BufferResponses
+// of the request's Config is true, except for streaming handlers
+// like JSONStream, EventStream, Blob, File, and StaticFiles.
+// Errors returned after a handler wrote to the response are then
+// handled with a clean error response if the response was not committed yet,
+// else the error is logged and the response is aborted with http.ErrAbortHandler.
+//
+// Middleware can use Written and Status to inspect the response:
+//
+//	buffered := respond.NewBufferedResponseWriter(w, 64<<10)
+//	next.ServeHTTP(buffered, r)
+//	if !buffered.Written() {
+//	    httperr.Handle(httperr.NotFound, buffered, r)
+//	}
+//	buffered.Commit()
+type BufferedResponseWriter struct {
+	writer      http.ResponseWriter
+	original    http.Header
+	header      http.Header
+	body        bytes.Buffer
+	size        int
+	statusCode  int
+	wroteHeader bool
+	committed   bool
+}
+
+// NewBufferedResponseWriter returns a BufferedResponseWriter wrapping writer
+// that buffers up to size bytes of the body before it commits the response.
+// Zero or a negative size means no limit.
+func NewBufferedResponseWriter(writer http.ResponseWriter, size int) *BufferedResponseWriter {
+	original := writer.Header().Clone()
+	if original == nil {
+		original = make(http.Header)
+	}
+	return &BufferedResponseWriter{
+		writer:   writer,
+		original: original,
+		header:   original.Clone(),
+		size:     size,
+	}
+}
+
+// Header implements http.ResponseWriter
+func (w *BufferedResponseWriter) Header() http.Header {
+	return w.header
+}
+
+// WriteHeader implements http.ResponseWriter.
+// The status code is buffered until the response is committed,
+// informational 1xx status codes are written immediately
+// with the current headers.
+func (w *BufferedResponseWriter) WriteHeader(statusCode int) {
+	switch {
+	case w.committed:
+		if !w.wroteHeader && statusCode >= 200 {
+			w.statusCode = statusCode
+			w.wroteHeader = true
+		}
+		w.writer.WriteHeader(statusCode)
+	case statusCode < 200:
+		copyHeader(w.writer.Header(), w.header)
+		w.writer.WriteHeader(statusCode)
+	case !w.wroteHeader:
+		w.statusCode = statusCode
+		w.wroteHeader = true
+	}
+}
+
+// Write implements http.ResponseWriter.
+// The body is buffered until the response is committed.
+func (w *BufferedResponseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if !w.committed && w.size > 0 && w.body.Len()+len(b) > w.size {
+		if err := w.Commit(); err != nil {
+			return 0, err
+		}
+	}
+	if w.committed {
+		return w.writer.Write(b)
+	}
+	return w.body.Write(b)
+}
+
+// Written returns true if a status code or body
+// has been written, buffered or not.
+func (w *BufferedResponseWriter) Written() bool {
+	return w.wroteHeader
+}
+
+// Status returns the status code that has been written,
+// buffered or not, or zero if nothing has been written.
+func (w *BufferedResponseWriter) Status() int {
+	return w.statusCode
+}
+
+// Committed returns true if the response has been written
+// to the wrapped http.ResponseWriter and can't be discarded anymore.
+func (w *BufferedResponseWriter) Committed() bool {
+	return w.committed
+}
+
+// Discard drops the buffered status code, body, and all headers
+// set since the BufferedResponseWriter was created,
+// so that a different response can be written.
+// It returns false if the response has already been committed.
+func (w *BufferedResponseWriter) Discard() bool {
+	if w.committed {
+		return false
+	}
+	w.header = w.original.Clone()
+	w.body.Reset()
+	w.statusCode = 0
+	w.wroteHeader = false
+	return true
+}
+
+// Commit writes the buffered headers, status code, and body
+// to the wrapped http.ResponseWriter.
+// Following writes are passed through unbuffered.
+// Commit does nothing if the response is already committed.
+func (w *BufferedResponseWriter) Commit() error {
+	if w.committed {
+		return nil
+	}
+	w.committed = true
+	header := w.writer.Header()
+	copyHeader(header, w.header)
+	w.header = header
+	if w.wroteHeader {
+		w.writer.WriteHeader(w.statusCode)
+	}
+	if w.body.Len() == 0 {
+		return nil
+	}
+	_, err := w.writer.Write(w.body.Bytes())
+	w.body = bytes.Buffer{}
+	return err
+}
+
+// Flush implements http.Flusher.
+// It commits the response and flushes the wrapped http.ResponseWriter.
+func (w *BufferedResponseWriter) Flush() {
+	w.FlushError() //#nosec G104
+}
+
+// FlushError commits the response and flushes the wrapped
+// http.ResponseWriter, it is used by http.ResponseController.
+func (w *BufferedResponseWriter) FlushError() error {
+	if err := w.Commit(); err != nil {
+		return err
+	}
+	return http.NewResponseController(w.writer).Flush()
+}
+
+// Unwrap returns the wrapped http.ResponseWriter
+// for http.ResponseController
+func (w *BufferedResponseWriter) Unwrap() http.ResponseWriter {
+	return w.writer
+}
+
+// copyHeader replaces all values of dst with the values of src
+func copyHeader(dst, src http.Header) {
+	for key := range dst {
+		if _, ok := src[key]; !ok {
+			delete(dst, key)
+		}
+	}
+	for key, values := range src {
+		dst[key] = values
+	}
+}
+
+// bufferResponse returns writer wrapped in a BufferedResponseWriter
+// if BufferResponses of the Config is true, and a function
+// that commits the buffered response after the handler returned
+func (c *Config) bufferResponse(writer http.ResponseWriter) (http.ResponseWriter, func()) {
+	if _, ok := writer.(*BufferedResponseWriter); ok || !c.BufferResponses {
+		return writer, func() {}
+	}
+	buffered := NewBufferedResponseWriter(writer, c.ResponseBufferSize)
+	return buffered, func() { buffered.Commit() } //#nosec G104
+}
+
+// discardResponse discards the buffered response if writer
+// is a BufferedResponseWriter that has not been committed yet
+func discardResponse(writer http.ResponseWriter) bool {
+	buffered, ok := writer.(*BufferedResponseWriter)
+	return ok && buffered.Discard()
+}
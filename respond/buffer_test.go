@@ -0,0 +1,225 @@
+package respond
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ungerik/go-httpx/httperr"
+)
+
+func ExampleBufferedResponseWriter() {
+	config := DefaultConfig()
+	config.BufferResponses = true
+
+	handler := config.Bind(Error(func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte("partial output")) //#nosec G104
+		return errors.New("export failed")
+	}))
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/export", nil))
+	fmt.Println(recorder.Code, recorder.Body.String())
+
+	// Output:
+	// 500 Internal Server Error
+}
+
+func TestBufferedResponseWriter(t *testing.T) {
+	tests := []struct {
+		name          string
+		size          int
+		write         func(w *BufferedResponseWriter)
+		wantCommitted bool
+		wantStatus    int
+		wantCode      int
+		wantBody      string
+	}{
+		{
+			name:       "nothing written",
+			size:       8,
+			write:      func(w *BufferedResponseWriter) {},
+			wantStatus: 0,
+			wantCode:   http.StatusOK,
+		},
+		{
+			name: "buffered",
+			size: 8,
+			write: func(w *BufferedResponseWriter) {
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte("12345678")) //#nosec G104
+			},
+			wantStatus: http.StatusCreated,
+			wantCode:   http.StatusOK,
+		},
+		{
+			name: "overflow commits",
+			size: 8,
+			write: func(w *BufferedResponseWriter) {
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte("12345")) //#nosec G104
+				w.Write([]byte("6789"))  //#nosec G104
+			},
+			wantCommitted: true,
+			wantStatus:    http.StatusCreated,
+			wantCode:      http.StatusCreated,
+			wantBody:      "123456789",
+		},
+		{
+			name: "no limit",
+			size: 0,
+			write: func(w *BufferedResponseWriter) {
+				w.Write(make([]byte, 1<<20)) //#nosec G104
+			},
+			wantStatus: http.StatusOK,
+			wantCode:   http.StatusOK,
+		},
+		{
+			name: "first status code wins",
+			size: 8,
+			write: func(w *BufferedResponseWriter) {
+				w.WriteHeader(http.StatusAccepted)
+				w.WriteHeader(http.StatusNotFound)
+				w.Commit() //#nosec G104
+			},
+			wantCommitted: true,
+			wantStatus:    http.StatusAccepted,
+			wantCode:      http.StatusAccepted,
+		},
+		{
+			name: "flush commits",
+			size: 8,
+			write: func(w *BufferedResponseWriter) {
+				w.Write([]byte("a")) //#nosec G104
+				w.Flush()
+			},
+			wantCommitted: true,
+			wantStatus:    http.StatusOK,
+			wantCode:      http.StatusOK,
+			wantBody:      "a",
+		},
+		{
+			name: "status after empty commit",
+			size: 8,
+			write: func(w *BufferedResponseWriter) {
+				w.Commit() //#nosec G104
+				w.WriteHeader(http.StatusAccepted)
+				w.Write([]byte("a")) //#nosec G104
+			},
+			wantCommitted: true,
+			wantStatus:    http.StatusAccepted,
+			wantCode:      http.StatusAccepted,
+			wantBody:      "a",
+		},
+		{
+			name: "discard",
+			size: 8,
+			write: func(w *BufferedResponseWriter) {
+				w.Header().Set("X-Partial", "1")
+				w.Write([]byte("partial")) //#nosec G104
+				w.Discard()
+				w.WriteHeader(http.StatusConflict)
+				w.Commit() //#nosec G104
+			},
+			wantCommitted: true,
+			wantStatus:    http.StatusConflict,
+			wantCode:      http.StatusConflict,
+		},
+		{
+			name: "discard after commit",
+			size: 8,
+			write: func(w *BufferedResponseWriter) {
+				w.Write([]byte("committed")) //#nosec G104
+				if w.Discard() {
+					panic("Discard after commit returned true")
+				}
+			},
+			wantCommitted: true,
+			wantStatus:    http.StatusOK,
+			wantCode:      http.StatusOK,
+			wantBody:      "committed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			buffered := NewBufferedResponseWriter(recorder, tt.size)
+			tt.write(buffered)
+			if buffered.Committed() != tt.wantCommitted {
+				t.Errorf("Committed() = %t, want %t", buffered.Committed(), tt.wantCommitted)
+			}
+			if buffered.Status() != tt.wantStatus {
+				t.Errorf("Status() = %d, want %d", buffered.Status(), tt.wantStatus)
+			}
+			if recorder.Code != tt.wantCode {
+				t.Errorf("wrapped writer status = %d, want %d", recorder.Code, tt.wantCode)
+			}
+			if recorder.Body.String() != tt.wantBody {
+				t.Errorf("wrapped writer body = %q, want %q", recorder.Body.String(), tt.wantBody)
+			}
+			if recorder.Header().Get("X-Partial") != "" {
+				t.Error("discarded header was written")
+			}
+		})
+	}
+}
+
+func TestBufferedResponseAbortLogsStatus(t *testing.T) {
+	var loggedStatus int
+	defer func(logger httperr.ErrorLogger) { httperr.Logger = logger }(httperr.Logger)
+	httperr.Logger = httperr.ErrorLoggerFunc(func(request *http.Request, statusCode int, err error) {
+		loggedStatus = statusCode
+	})
+
+	config := DefaultConfig()
+	config.BufferResponses = true
+	config.ResponseBufferSize = 4
+	tests := []struct {
+		name       string
+		handler    http.Handler
+		wantStatus int
+	}{
+		{
+			name: "error after commit",
+			handler: Error(func(w http.ResponseWriter, r *http.Request) error {
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte("more than 4 bytes")) //#nosec G104
+				return errors.New("failed")
+			}),
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name: "CSV error after commit",
+			handler: CSV(func(w http.ResponseWriter, r *http.Request) (any, error) {
+				return Accepted(CSVRows(func(yield func([]string) error) error {
+					if err := yield([]string{strings.Repeat("x", 8192)}); err != nil {
+						return err
+					}
+					return errors.New("failed")
+				})), nil
+			}),
+			wantStatus: http.StatusAccepted,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loggedStatus = 0
+			recorder := httptest.NewRecorder()
+			func() {
+				defer func() {
+					if r := recover(); r != http.ErrAbortHandler {
+						t.Errorf("recovered %v, want http.ErrAbortHandler", r)
+					}
+				}()
+				config.Bind(tt.handler).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+			}()
+			if loggedStatus != tt.wantStatus {
+				t.Errorf("logged status %d, want %d", loggedStatus, tt.wantStatus)
+			}
+		})
+	}
+}
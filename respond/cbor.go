@@ -0,0 +1,159 @@
+package respond
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"net/http"
+
+	"github.com/ungerik/go-httpx/contenttype"
+)
+
+// CBOR is a handler type for functions that return data to be encoded as CBOR.
+// The returned data is encoded with EncodeCBOR and written with
+// Content-Type: application/cbor. Any error is handled by httperr.Handle.
+// Return a Result to control the status code and headers of the response.
+//
+// Example:
+//
+//	http.Handle("/api/devices/{id}/state", respond.CBOR(func(w http.ResponseWriter, r *http.Request) (any, error) {
+//	    return db.GetDeviceState(r.Context(), r.PathValue("id"))
+//	}))
+type CBOR func(http.ResponseWriter, *http.Request) (response any, err error)
+
+// ServeHTTP implements http.Handler for CBOR.
+// It calls the handler function, handles any error, and encodes the response as CBOR.
+// If CatchPanics of the request's Config is true, panics are recovered and handled as errors.
+func (handlerFunc CBOR) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	config := RequestConfig(request)
+	writer, commit := config.bufferResponse(writer)
+	defer commit()
+	if config.CatchPanics {
+		defer config.recoverPanic(writer, request)
+	}
+
+	response, err := handlerFunc(writer, request)
+	if config.HandleError(err, writer, request) {
+		return
+	}
+
+	config.WriteCBOR(writer, request, response)
+}
+
+// WriteCBOR encodes the response as CBOR and writes it with the appropriate content type.
+// If encoding fails, an internal server error is written.
+func WriteCBOR(writer http.ResponseWriter, response any) {
+	DefaultConfig().WriteCBOR(writer, nil, response)
+}
+
+// EncodeCBOR encodes the response as CBOR according to RFC 8949
+// using the shortest encoding of integers and lengths
+// and definite length arrays and maps.
+//
+// Values are encoded like by encoding/json, so struct fields
+// are named by their cbor tag or else by their json tag
+// and types implementing json.Marshaler or encoding.TextMarshaler
+// are encoded as their JSON or text representation.
+// Byte slices are encoded as byte strings and structs and maps as maps with text keys.
+func EncodeCBOR(response any) ([]byte, error) {
+	value, err := genericValue(response, "cbor")
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	writeCBOR(&buf, value)
+	return buf.Bytes(), nil
+}
+
+// WriteCBOR encodes the response as CBOR and writes it with the appropriate content type.
+// If encoding fails, an internal server error is written.
+// If response is a Result, then its status code and headers
+// are written and its body is encoded.
+// If request is not nil, then the conditional headers of GET and HEAD
+// requests are evaluated and an ETag is added if AutoETag is true.
+func (c *Config) WriteCBOR(writer http.ResponseWriter, request *http.Request, response any) {
+	c.WriteEncoded(writer, request, cborEncoder, response)
+}
+
+var cborEncoder = Encoder{
+	ContentType: contenttype.CBOR,
+	Encode:      EncodeCBOR,
+}
+
+// CBOR major types
+const (
+	cborUnsignedInt = 0 << 5
+	cborNegativeInt = 1 << 5
+	cborByteString  = 2 << 5
+	cborTextString  = 3 << 5
+	cborArray       = 4 << 5
+	cborMap         = 5 << 5
+	cborSimple      = 7 << 5
+)
+
+func writeCBOR(buf *bytes.Buffer, value any) {
+	switch x := value.(type) {
+	case nil:
+		buf.WriteByte(cborSimple | 22)
+	case bool:
+		if x {
+			buf.WriteByte(cborSimple | 21)
+		} else {
+			buf.WriteByte(cborSimple | 20)
+		}
+	case int64:
+		if x < 0 {
+			writeCBORHead(buf, cborNegativeInt, uint64(-(x + 1)))
+		} else {
+			writeCBORHead(buf, cborUnsignedInt, uint64(x))
+		}
+	case uint64:
+		writeCBORHead(buf, cborUnsignedInt, x)
+	case float32:
+		buf.WriteByte(cborSimple | 26)
+		buf.Write(binary.BigEndian.AppendUint32(nil, math.Float32bits(x)))
+	case float64:
+		buf.WriteByte(cborSimple | 27)
+		buf.Write(binary.BigEndian.AppendUint64(nil, math.Float64bits(x)))
+	case string:
+		writeCBORHead(buf, cborTextString, uint64(len(x)))
+		buf.WriteString(x)
+	case []byte:
+		writeCBORHead(buf, cborByteString, uint64(len(x)))
+		buf.Write(x)
+	case []any:
+		writeCBORHead(buf, cborArray, uint64(len(x)))
+		for _, item := range x {
+			writeCBOR(buf, item)
+		}
+	case genericMap:
+		writeCBORHead(buf, cborMap, uint64(len(x)))
+		for _, entry := range x {
+			writeCBOR(buf, entry.Key)
+			writeCBOR(buf, entry.Value)
+		}
+	default:
+		writeCBOR(buf, fmt.Sprint(x))
+	}
+}
+
+// writeCBORHead writes the initial byte of a data item
+// with majorType followed by the shortest encoding of argument
+func writeCBORHead(buf *bytes.Buffer, majorType byte, argument uint64) {
+	switch {
+	case argument < 24:
+		buf.WriteByte(majorType | byte(argument))
+	case argument <= math.MaxUint8:
+		buf.Write([]byte{majorType | 24, byte(argument)})
+	case argument <= math.MaxUint16:
+		buf.WriteByte(majorType | 25)
+		buf.Write(binary.BigEndian.AppendUint16(nil, uint16(argument)))
+	case argument <= math.MaxUint32:
+		buf.WriteByte(majorType | 26)
+		buf.Write(binary.BigEndian.AppendUint32(nil, uint32(argument)))
+	default:
+		buf.WriteByte(majorType | 27)
+		buf.Write(binary.BigEndian.AppendUint64(nil, argument))
+	}
+}
@@ -0,0 +1,74 @@
+package respond
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
+
+func ExampleEncodeCBOR() {
+	type Reading struct {
+		Sensor string  `json:"sensor"`
+		Value  float64 `json:"value"`
+		Raw    []byte  `json:"raw,omitempty"`
+	}
+	reading := Reading{Sensor: "t1", Value: 21.5, Raw: []byte{0x01, 0x02}}
+
+	cbor, err := EncodeCBOR(reading)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("% x\n", cbor)
+
+	// Output:
+	// a3 66 73 65 6e 73 6f 72 62 74 31 65 76 61 6c 75 65 fb 40 35 80 00 00 00 00 00 63 72 61 77 42 01 02
+}
+
+func TestEncodeCBOR(t *testing.T) {
+	tests := []struct {
+		name       string
+		value      any
+		wantPrefix []byte
+		wantLen    int
+	}{
+		{"nil", nil, []byte{0xf6}, 1},
+		{"true", true, []byte{0xf5}, 1},
+		{"false", false, []byte{0xf4}, 1},
+		{"uint 23", 23, []byte{0x17}, 1},
+		{"uint8", 24, []byte{0x18, 0x18}, 2},
+		{"uint16", 256, []byte{0x19, 0x01, 0x00}, 3},
+		{"uint32", 1 << 16, []byte{0x1a, 0x00, 0x01, 0x00, 0x00}, 5},
+		{"uint64", uint64(math.MaxUint64), []byte{0x1b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, 9},
+		{"negative -1", -1, []byte{0x20}, 1},
+		{"negative -24", -24, []byte{0x37}, 1},
+		{"negative -25", -25, []byte{0x38, 0x18}, 2},
+		{"negative -256", -256, []byte{0x38, 0xff}, 2},
+		{"negative -257", -257, []byte{0x39, 0x01, 0x00}, 3},
+		{"min int64", int64(math.MinInt64), []byte{0x3b, 0x7f, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, 9},
+		{"float32", float32(1.5), []byte{0xfa, 0x3f, 0xc0, 0x00, 0x00}, 5},
+		{"float64", 1.5, []byte{0xfb, 0x3f, 0xf8, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, 9},
+		{"empty string", "", []byte{0x60}, 1},
+		{"string 23", strings.Repeat("a", 23), []byte{0x77, 'a'}, 24},
+		{"string 24", strings.Repeat("a", 24), []byte{0x78, 0x18, 'a'}, 26},
+		{"string 256", strings.Repeat("a", 256), []byte{0x79, 0x01, 0x00, 'a'}, 259},
+		{"bytes", []byte{1, 2}, []byte{0x42, 0x01, 0x02}, 3},
+		{"empty array", []int{}, []byte{0x80}, 1},
+		{"array 24", make([]int, 24), []byte{0x98, 0x18, 0x00}, 26},
+		{"array 256", make([]bool, 256), []byte{0x99, 0x01, 0x00, 0xf4}, 259},
+		{"empty map", map[string]int{}, []byte{0xa0}, 1},
+		{"map", map[string]int{"a": -1}, []byte{0xa1, 0x61, 'a', 0x20}, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EncodeCBOR(tt.value)
+			if err != nil {
+				t.Fatalf("EncodeCBOR() error = %v", err)
+			}
+			if !bytes.HasPrefix(got, tt.wantPrefix) || len(got) != tt.wantLen {
+				t.Errorf("EncodeCBOR() = % x (%d bytes), want prefix % x and %d bytes", got[:min(len(got), 16)], len(got), tt.wantPrefix, tt.wantLen)
+			}
+		})
+	}
+}
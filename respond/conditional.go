@@ -0,0 +1,244 @@
+package respond
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ungerik/go-httpx/httperr"
+)
+
+// ETag returns a strong entity tag for body
+// in the quoted format of the ETag header.
+func ETag(body []byte) string {
+	hash := sha256.Sum256(body)
+	return `"` + base64.RawURLEncoding.EncodeToString(hash[:16]) + `"`
+}
+
+// CheckPreconditions evaluates the If-Match, If-Unmodified-Since,
+// If-None-Match, and If-Modified-Since headers of the request
+// in the order of RFC 9110 section 13.2.2 against the current
+// etag and lastModified time of the requested resource.
+// Pass an empty etag and a zero lastModified if the resource doesn't exist.
+// The etag is quoted if it is not already in the format of the ETag header.
+//
+// Handlers should call it before modifying a resource with unsafe
+// methods like PUT or DELETE, because the handlers of this package
+// only evaluate preconditions for GET and HEAD requests
+// after the handler function returned.
+//
+// It returns nil if the request should be processed,
+// httperr.PreconditionFailed wrapped with httperr.DontLog
+// if a precondition failed, or an error that writes a
+// 304 Not Modified response with the passed validators
+// for GET and HEAD requests of unchanged resources.
+//
+// Example:
+//
+//	http.Handle("PUT /api/users/{id}", respond.JSON(func(w http.ResponseWriter, r *http.Request) (any, error) {
+//	    user, err := db.GetUser(r.PathValue("id"))
+//	    if err != nil {
+//	        return nil, err
+//	    }
+//	    err = respond.CheckPreconditions(r, user.Version, user.Modified)
+//	    if err != nil {
+//	        return nil, err // 412 Precondition Failed
+//	    }
+//	    return db.UpdateUser(r, user)
+//	}))
+func CheckPreconditions(request *http.Request, etag string, lastModified time.Time) error {
+	etag = quoteETag(etag)
+	switch evaluatePreconditions(request, etag, lastModified) {
+	case http.StatusNotModified:
+		return httperr.DontLog(notModified{etag: etag, lastModified: lastModified})
+	case http.StatusPreconditionFailed:
+		return httperr.DontLog(httperr.PreconditionFailed)
+	}
+	return nil
+}
+
+// evaluatePreconditions returns 304 Not Modified,
+// 412 Precondition Failed, or 0 if the request should be processed
+func evaluatePreconditions(request *http.Request, etag string, lastModified time.Time) int {
+	exists := etag != "" || !lastModified.IsZero()
+	lastModified = lastModified.Truncate(time.Second)
+	isGetOrHead := request.Method == http.MethodGet || request.Method == http.MethodHead
+
+	if ifMatch := strings.Join(request.Header.Values("If-Match"), ","); ifMatch != "" {
+		if !exists || !matchETags(ifMatch, etag, false) {
+			return http.StatusPreconditionFailed
+		}
+	} else if since, err := http.ParseTime(request.Header.Get("If-Unmodified-Since")); err == nil && !lastModified.IsZero() {
+		if lastModified.After(since) {
+			return http.StatusPreconditionFailed
+		}
+	}
+
+	if ifNoneMatch := strings.Join(request.Header.Values("If-None-Match"), ","); ifNoneMatch != "" {
+		if exists && matchETags(ifNoneMatch, etag, true) {
+			if isGetOrHead {
+				return http.StatusNotModified
+			}
+			return http.StatusPreconditionFailed
+		}
+	} else if since, err := http.ParseTime(request.Header.Get("If-Modified-Since")); err == nil && isGetOrHead && !lastModified.IsZero() {
+		if !lastModified.After(since) {
+			return http.StatusNotModified
+		}
+	}
+	return 0
+}
+
+// matchETags returns true if the comma separated list
+// of entity tags or "*" matches etag.
+// Weak comparison ignores the W/ prefix,
+// strong comparison never matches weak tags.
+func matchETags(list, etag string, weak bool) bool {
+	for {
+		list = strings.TrimLeft(list, " \t,")
+		if list == "" {
+			return false
+		}
+		if list[0] == '*' {
+			return true
+		}
+		tag, rest, ok := scanETag(list)
+		if !ok {
+			return false
+		}
+		list = rest
+		if etag == "" {
+			continue
+		}
+		if weak {
+			if strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		} else if tag == etag && !strings.HasPrefix(tag, "W/") {
+			return true
+		}
+	}
+}
+
+// scanETag returns the entity tag at the start of s
+// and the rest of s after it
+func scanETag(s string) (tag, rest string, ok bool) {
+	start := 0
+	if strings.HasPrefix(s, "W/") {
+		start = 2
+	}
+	if len(s) <= start || s[start] != '"' {
+		return "", "", false
+	}
+	end := strings.IndexByte(s[start+1:], '"')
+	if end < 0 {
+		return "", "", false
+	}
+	end += start + 2
+	return s[:end], s[end:], true
+}
+
+// quoteETag returns etag in the quoted format
+// of the ETag header if it is not already quoted
+func quoteETag(etag string) string {
+	if etag == "" || strings.HasPrefix(etag, `"`) || strings.HasPrefix(etag, `W/"`) {
+		return etag
+	}
+	return `"` + etag + `"`
+}
+
+// handlePreconditions evaluates the preconditions of the request
+// against the ETag and Last-Modified headers of the response
+// and writes a 304 Not Modified response or handles a
+// 412 Precondition Failed error if the response should not be written.
+func (c *Config) handlePreconditions(writer http.ResponseWriter, request *http.Request) bool {
+	etag := writer.Header().Get("ETag")
+	lastModified, _ := http.ParseTime(writer.Header().Get("Last-Modified"))
+	if etag == "" && lastModified.IsZero() {
+		return false
+	}
+	switch evaluatePreconditions(request, etag, lastModified) {
+	case http.StatusNotModified:
+		writeNotModified(writer)
+		return true
+	case http.StatusPreconditionFailed:
+		return c.HandleError(httperr.DontLog(httperr.PreconditionFailed), writer, request)
+	}
+	return false
+}
+
+// isConditional returns true if the response with statusCode
+// to request can be answered with 304 Not Modified,
+// which is the case for successful GET and HEAD requests
+func isConditional(request *http.Request, statusCode int) bool {
+	return request != nil &&
+		(statusCode == 0 || statusCode == http.StatusOK) &&
+		(request.Method == http.MethodGet || request.Method == http.MethodHead)
+}
+
+// writeBody writes body with statusCode.
+// For successful GET and HEAD requests an ETag is added if AutoETag
+// of the Config is true and the preconditions of the request are evaluated.
+// A nil request skips conditional request handling.
+func (c *Config) writeBody(writer http.ResponseWriter, request *http.Request, statusCode int, body []byte) {
+	if isConditional(request, statusCode) {
+		if c.AutoETag && writer.Header().Get("ETag") == "" {
+			writer.Header().Set("ETag", ETag(body))
+		}
+		if c.handlePreconditions(writer, request) {
+			return
+		}
+	}
+	if statusCode != 0 {
+		writer.WriteHeader(statusCode)
+	}
+	writer.Write(body) //#nosec G104
+}
+
+// writeDeferred writes body with contentType and the status code
+// recorded by deferred like done by the HTML and Plaintext handlers
+func (c *Config) writeDeferred(deferred *deferredStatusWriter, request *http.Request, contentType string, body []byte) {
+	if deferred.writeBodyless(len(body)) {
+		return
+	}
+	deferred.Header().Add("Content-Type", contentType)
+	if deferred.wroteHeader {
+		deferred.Write(body) //#nosec G104
+		return
+	}
+	c.writeBody(deferred.ResponseWriter, request, deferred.statusCode, body)
+}
+
+func writeNotModified(writer http.ResponseWriter) {
+	header := writer.Header()
+	header.Del("Content-Type")
+	header.Del("Content-Length")
+	writer.WriteHeader(http.StatusNotModified)
+}
+
+// notModified is returned as error by CheckPreconditions
+// and writes a 304 Not Modified response
+type notModified struct {
+	etag         string
+	lastModified time.Time
+}
+
+func (notModified) Error() string {
+	return http.StatusText(http.StatusNotModified)
+}
+
+func (notModified) StatusCode() int {
+	return http.StatusNotModified
+}
+
+func (n notModified) ServeHTTP(writer http.ResponseWriter, _ *http.Request) {
+	if n.etag != "" {
+		writer.Header().Set("ETag", n.etag)
+	}
+	if !n.lastModified.IsZero() {
+		writer.Header().Set("Last-Modified", n.lastModified.UTC().Format(http.TimeFormat))
+	}
+	writeNotModified(writer)
+}
@@ -0,0 +1,149 @@
+package respond
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ungerik/go-httpx/httperr"
+)
+
+func ExampleResult() {
+	modified := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	handler := JSON(func(w http.ResponseWriter, r *http.Request) (any, error) {
+		return &Result{
+			Body:         map[string]string{"status": "ok"},
+			ETag:         "v42",
+			LastModified: modified,
+		}, nil
+	})
+
+	for _, header := range []http.Header{
+		{},
+		{"If-None-Match": {`"v41", "v42"`}},
+		{"If-Modified-Since": {modified.Format(http.TimeFormat)}},
+		{"If-Match": {`"v41"`}},
+	} {
+		request := httptest.NewRequest(http.MethodGet, "/status", nil)
+		request.Header = header
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		fmt.Println(recorder.Code, recorder.Header().Get("ETag"), recorder.Body.Len() > 0)
+	}
+
+	// Output:
+	// 200 "v42" true
+	// 304 "v42" false
+	// 304 "v42" false
+	// 412 "v42" true
+}
+
+func TestCheckPreconditions(t *testing.T) {
+	modified := time.Date(2026, 10, 1, 12, 0, 0, 500, time.UTC)
+	before := modified.Add(-time.Hour).Format(http.TimeFormat)
+	at := modified.Format(http.TimeFormat)
+	tests := []struct {
+		name         string
+		method       string
+		header       http.Header
+		etag         string
+		lastModified time.Time
+		wantStatus   int
+	}{
+		{"no preconditions", http.MethodGet, http.Header{}, "v1", modified, 0},
+		{"If-None-Match matches GET", http.MethodGet, http.Header{"If-None-Match": {`"v1"`}}, "v1", time.Time{}, 304},
+		{"If-None-Match matches HEAD", http.MethodHead, http.Header{"If-None-Match": {`"v1"`}}, "v1", time.Time{}, 304},
+		{"If-None-Match matches PUT", http.MethodPut, http.Header{"If-None-Match": {`"v1"`}}, "v1", time.Time{}, 412},
+		{"If-None-Match star DELETE", http.MethodDelete, http.Header{"If-None-Match": {"*"}}, "v1", time.Time{}, 412},
+		{"If-None-Match star missing resource PUT", http.MethodPut, http.Header{"If-None-Match": {"*"}}, "", time.Time{}, 0},
+		{"If-None-Match weak comparison", http.MethodGet, http.Header{"If-None-Match": {`W/"v1"`}}, "v1", time.Time{}, 304},
+		{"If-None-Match multiple headers", http.MethodGet, http.Header{"If-None-Match": {`"v0"`, `"v1"`}}, "v1", time.Time{}, 304},
+		{"If-None-Match no match", http.MethodGet, http.Header{"If-None-Match": {`"v2"`}}, "v1", time.Time{}, 0},
+		{"If-None-Match malformed", http.MethodGet, http.Header{"If-None-Match": {`v1`}}, "v1", time.Time{}, 0},
+		{"If-Match matches PUT", http.MethodPut, http.Header{"If-Match": {`"v0", "v1"`}}, "v1", time.Time{}, 0},
+		{"If-Match mismatch PUT", http.MethodPut, http.Header{"If-Match": {`"v2"`}}, "v1", time.Time{}, 412},
+		{"If-Match weak tag never matches", http.MethodPut, http.Header{"If-Match": {`W/"v1"`}}, "v1", time.Time{}, 412},
+		{"If-Match weak resource never matches", http.MethodPut, http.Header{"If-Match": {`"v1"`}}, `W/"v1"`, time.Time{}, 412},
+		{"If-Match star existing", http.MethodPatch, http.Header{"If-Match": {"*"}}, "v1", time.Time{}, 0},
+		{"If-Match star missing resource", http.MethodPut, http.Header{"If-Match": {"*"}}, "", time.Time{}, 412},
+		{"If-Unmodified-Since modified POST", http.MethodPost, http.Header{"If-Unmodified-Since": {before}}, "", modified, 412},
+		{"If-Unmodified-Since unchanged POST", http.MethodPost, http.Header{"If-Unmodified-Since": {at}}, "", modified, 0},
+		{"If-Unmodified-Since ignored with If-Match", http.MethodPut, http.Header{"If-Match": {`"v1"`}, "If-Unmodified-Since": {before}}, "v1", modified, 0},
+		{"If-Unmodified-Since invalid date", http.MethodPut, http.Header{"If-Unmodified-Since": {"yesterday"}}, "", modified, 0},
+		{"If-Modified-Since unchanged", http.MethodGet, http.Header{"If-Modified-Since": {at}}, "", modified, 304},
+		{"If-Modified-Since changed", http.MethodGet, http.Header{"If-Modified-Since": {before}}, "", modified, 0},
+		{"If-Modified-Since ignored for POST", http.MethodPost, http.Header{"If-Modified-Since": {at}}, "", modified, 0},
+		{"If-Modified-Since ignored with If-None-Match", http.MethodGet, http.Header{"If-None-Match": {`"v2"`}, "If-Modified-Since": {at}}, "v1", modified, 0},
+		{"If-Match failure before If-None-Match", http.MethodGet, http.Header{"If-Match": {`"v2"`}, "If-None-Match": {`"v1"`}}, "v1", time.Time{}, 412},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(tt.method, "/", nil)
+			request.Header = tt.header
+			err := CheckPreconditions(request, tt.etag, tt.lastModified)
+			status := 0
+			if err != nil {
+				status = httperr.StatusCode(err)
+				if httperr.ShouldLog(err) {
+					t.Errorf("precondition error should not be logged")
+				}
+			}
+			if status != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", status, tt.wantStatus)
+			}
+			if status != http.StatusNotModified {
+				return
+			}
+			recorder := httptest.NewRecorder()
+			recorder.Header().Set("Content-Type", "text/plain")
+			httperr.Handle(err, recorder, request)
+			if recorder.Code != http.StatusNotModified || recorder.Body.Len() > 0 || recorder.Header().Get("Content-Type") != "" {
+				t.Errorf("response = %d %v %q", recorder.Code, recorder.Header(), recorder.Body)
+			}
+			if tt.etag != "" && recorder.Header().Get("ETag") != quoteETag(tt.etag) {
+				t.Errorf("ETag = %q", recorder.Header().Get("ETag"))
+			}
+		})
+	}
+}
+
+func TestAutoETag(t *testing.T) {
+	body := map[string]int{"id": 1}
+	encoded, _ := DefaultConfig().EncodeJSON(body)
+	tests := []struct {
+		name       string
+		method     string
+		status     int
+		autoETag   bool
+		header     http.Header
+		wantStatus int
+		wantETag   string
+	}{
+		{"GET", http.MethodGet, 0, true, http.Header{}, 200, ETag(encoded)},
+		{"GET If-None-Match", http.MethodGet, 0, true, http.Header{"If-None-Match": {ETag(encoded)}}, 304, ETag(encoded)},
+		{"disabled", http.MethodGet, 0, false, http.Header{"If-None-Match": {ETag(encoded)}}, 200, ""},
+		{"POST", http.MethodPost, 0, true, http.Header{}, 200, ""},
+		{"non 200 status", http.MethodGet, http.StatusCreated, true, http.Header{"If-None-Match": {"*"}}, 201, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.AutoETag = tt.autoETag
+			handler := JSON(func(w http.ResponseWriter, r *http.Request) (any, error) {
+				return Status(tt.status, body), nil
+			})
+			request := httptest.NewRequest(tt.method, "/", nil)
+			request.Header = tt.header
+			recorder := httptest.NewRecorder()
+			config.Bind(handler).ServeHTTP(recorder, request)
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+			if got := recorder.Header().Get("ETag"); got != tt.wantETag {
+				t.Errorf("ETag = %q, want %q", got, tt.wantETag)
+			}
+		})
+	}
+}
@@ -6,11 +6,15 @@
 // with appropriate content types.
 //
 // Key features:
-//   - Automatic response serialization (JSON, XML, HTML, plain text)
+//   - Automatic response serialization (JSON, XML, YAML, MessagePack, CBOR, HTML, plain text, CSV)
+//   - Rendering of html/template pages with layouts and error pages
 //   - Built-in panic recovery
 //   - Automatic error handling via httperr
+//   - Automatic request body decoding (JSON, XML, forms)
 //   - Pretty-printing support for JSON and XML
-//   - Type-safe handler function types
+//   - Streaming of NDJSON, JSON arrays, and server-sent events
+//   - Per handler and per request configuration via Config
+//   - Type-safe handler function types including generic ones like JSONOf[T]
 //
 // Example usage:
 //
@@ -20,6 +24,16 @@
 //	}))
 package respond
 
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ungerik/go-httpx/httperr"
+)
+
 var (
 	// CatchPanics controls whether response handlers automatically recover from panics.
 	// When true (default), panics are caught and converted to 500 Internal Server Error responses.
@@ -34,4 +48,302 @@ var (
 	// PrettyPrintIndent is the string used for each indentation level when pretty-printing.
 	// Default is two spaces ("  ").
 	PrettyPrintIndent = "  "
+
+	// MaxRequestBodySize is the maximum number of bytes read
+	// from request bodies decoded by DecodeRequestBody
+	// and handlers like JSONIn. Zero or a negative value means no limit.
+	// Default is 10 MB.
+	MaxRequestBodySize int64 = 10 << 20
+
+	// MultipartMaxMemory is the maximum number of bytes of
+	// a multipart form that are stored in memory,
+	// the rest is stored in temporary files.
+	// Default is 32 MB like the net/http default.
+	MultipartMaxMemory int64 = 32 << 20
+
+	// DisallowUnknownFields controls whether DecodeRequestBody
+	// rejects JSON objects and forms with keys that don't match
+	// a field of the target type with a 400 Bad Request error.
+	// XML decoding always ignores unknown elements.
+	DisallowUnknownFields = false
+
+	// EventStreamKeepAlive is the interval in which EventStream handlers
+	// write a comment to keep idle connections open through proxies.
+	// Zero or a negative value disables keep-alive comments.
+	// Default is 15 seconds.
+	EventStreamKeepAlive = 15 * time.Second
+
+	// CSVDelimiter is the field delimiter used by CSV handlers.
+	// Default is a comma.
+	CSVDelimiter = ','
+
+	// CSVWithBOM controls whether CSV and TSV handlers write
+	// a UTF-8 byte order mark before the first row,
+	// so that Microsoft Excel detects the encoding.
+	// Default is false.
+	CSVWithBOM = false
+
+	// AutoETag controls whether handlers that encode the whole response
+	// body in memory, like JSON, XML, HTML, and Plaintext, add a strong ETag
+	// computed from the body to successful GET and HEAD responses
+	// that don't have an ETag yet.
+	// Default is false.
+	AutoETag = false
+
+	// BufferResponses controls whether handlers buffer responses
+	// with a BufferedResponseWriter, so that errors returned after
+	// a handler wrote to the response result in clean error responses.
+	// Default is false.
+	BufferResponses = false
+
+	// ResponseBufferSize is the maximum number of body bytes buffered
+	// if BufferResponses is true, larger responses are committed
+	// and can't be replaced with an error response anymore.
+	// Zero or a negative value means no limit.
+	// Default is 64 KB.
+	ResponseBufferSize = 64 << 10
+
+	// DefaultTemplates are the page templates rendered by Template handlers.
+	// Default is nil, so they have to be set before Template handlers are used.
+	DefaultTemplates *Templates
 )
+
+// Config carries the settings used by the handlers of this package.
+// The package level variables like PrettyPrint and CatchPanics
+// form the default configuration returned by DefaultConfig.
+//
+// Bind a handler to a Config to use different settings
+// for different handlers in the same process.
+// Start with a copy of the defaults from DefaultConfig,
+// because zero fields of a Config literal are used as they are:
+//
+//	public := respond.DefaultConfig()
+//	public.CatchPanics = true
+//	debug := respond.DefaultConfig()
+//	debug.PrettyPrint = true
+//	debug.PrettyPrintParam = "pretty"
+//
+//	mux.Handle("/api/users", public.Bind(respond.JSON(listUsers)))
+//	mux.Handle("/debug/users", debug.Bind(respond.JSON(listUsers)))
+//
+// A Config must not be modified after it has been bound to handlers.
+type Config struct {
+	// CatchPanics controls whether handlers recover from panics
+	// and handle them as errors.
+	CatchPanics bool
+
+	// PrettyPrint controls whether responses are formatted with indentation
+	// by encoders that support it like JSON and XML.
+	PrettyPrint bool
+
+	// PrettyPrintIndent is the string used for each indentation level when pretty-printing.
+	PrettyPrintIndent string
+
+	// PrettyPrintParam is the name of an optional URL query parameter
+	// that overrides PrettyPrint per request, like "pretty" for ?pretty=1.
+	// The value is parsed with strconv.ParseBool, an empty value means true.
+	// No query parameter is checked if PrettyPrintParam is empty.
+	PrettyPrintParam string
+
+	// Encoders offered by the Negotiated handler,
+	// the package level Encoders are used if nil.
+	Encoders []Encoder
+
+	// ErrorHandler handles errors returned by handlers,
+	// httperr.Handle is used if nil.
+	ErrorHandler httperr.Handler
+
+	// MaxRequestBodySize is the maximum number of bytes read from decoded
+	// request bodies. Zero or a negative value means no limit.
+	MaxRequestBodySize int64
+
+	// MultipartMaxMemory is the maximum number of bytes of
+	// a multipart form that are stored in memory.
+	MultipartMaxMemory int64
+
+	// DisallowUnknownFields controls whether JSON objects and forms
+	// with keys that don't match a field of the target type are rejected.
+	DisallowUnknownFields bool
+
+	// EventStreamKeepAlive is the interval of keep-alive comments
+	// written by EventStream handlers. Zero or a negative value
+	// disables keep-alive comments.
+	EventStreamKeepAlive time.Duration
+
+	// CSVDelimiter is the field delimiter used by CSV handlers,
+	// a comma is used if zero.
+	CSVDelimiter rune
+
+	// CSVWithBOM controls whether CSV and TSV handlers
+	// write a UTF-8 byte order mark before the first row.
+	CSVWithBOM bool
+
+	// AutoETag controls whether handlers add a strong ETag
+	// computed from the encoded body to successful GET and HEAD responses.
+	AutoETag bool
+
+	// BufferResponses controls whether handlers buffer responses
+	// with a BufferedResponseWriter.
+	BufferResponses bool
+
+	// ResponseBufferSize is the maximum number of buffered body bytes
+	// if BufferResponses is true. Zero or a negative value means no limit.
+	ResponseBufferSize int
+
+	// Templates are the page templates rendered by Template handlers,
+	// the package level DefaultTemplates are used if nil.
+	Templates *Templates
+}
+
+// DefaultConfig returns a new Config with the current
+// values of the package level configuration variables.
+func DefaultConfig() *Config {
+	return &Config{
+		CatchPanics:           CatchPanics,
+		PrettyPrint:           PrettyPrint,
+		PrettyPrintIndent:     PrettyPrintIndent,
+		MaxRequestBodySize:    MaxRequestBodySize,
+		MultipartMaxMemory:    MultipartMaxMemory,
+		DisallowUnknownFields: DisallowUnknownFields,
+		EventStreamKeepAlive:  EventStreamKeepAlive,
+		CSVDelimiter:          CSVDelimiter,
+		CSVWithBOM:            CSVWithBOM,
+		AutoETag:              AutoETag,
+		BufferResponses:       BufferResponses,
+		ResponseBufferSize:    ResponseBufferSize,
+	}
+}
+
+type configContextKey struct{}
+
+// WithConfig returns a shallow copy of request
+// with config attached to its context
+// so that RequestConfig will return it.
+func WithConfig(request *http.Request, config *Config) *http.Request {
+	return request.WithContext(context.WithValue(request.Context(), configContextKey{}, config))
+}
+
+// RequestConfig returns the Config attached to the request context
+// by WithConfig or Config.Bind, or DefaultConfig if there is none.
+// If PrettyPrintParam of the Config is set in the URL query of the request,
+// then a copy with PrettyPrint overridden by the parameter is returned.
+func RequestConfig(request *http.Request) *Config {
+	var config *Config
+	if request != nil {
+		config, _ = request.Context().Value(configContextKey{}).(*Config)
+	}
+	if config == nil {
+		config = DefaultConfig()
+	}
+	if config.PrettyPrintParam == "" || request == nil || request.URL == nil {
+		return config
+	}
+	values, ok := request.URL.Query()[config.PrettyPrintParam]
+	if !ok {
+		return config
+	}
+	pretty := true
+	if len(values) > 0 && values[0] != "" {
+		var err error
+		pretty, err = strconv.ParseBool(values[0])
+		if err != nil {
+			return config
+		}
+	}
+	if pretty == config.PrettyPrint {
+		return config
+	}
+	override := *config
+	override.PrettyPrint = pretty
+	if override.PrettyPrintIndent == "" {
+		override.PrettyPrintIndent = PrettyPrintIndent
+	}
+	return &override
+}
+
+// Bind returns a http.Handler that calls handler
+// with the Config attached to the request context.
+// The returned handler implements an Unwrap method
+// returning the passed handler for tooling.
+func (c *Config) Bind(handler http.Handler) http.Handler {
+	return boundHandler{config: c, handler: handler}
+}
+
+// HandleError handles err with ErrorHandler or httperr.Handle if ErrorHandler is nil.
+// It returns false if err is nil.
+// If writer is a BufferedResponseWriter, then its buffered response
+// is discarded before the error is handled, or if the response
+// has already been committed, err is logged and the response
+// is aborted by panicking with http.ErrAbortHandler.
+func (c *Config) HandleError(err error, writer http.ResponseWriter, request *http.Request) (handled bool) {
+	if err == nil {
+		return false
+	}
+	if buffered, ok := writer.(*BufferedResponseWriter); ok && !buffered.Discard() {
+		abortResponse(err, buffered.Status(), request)
+	}
+	if c.ErrorHandler == nil {
+		return httperr.Handle(err, writer, request)
+	}
+	return c.ErrorHandler.HandleError(err, writer, request)
+}
+
+// recoverPanic must be called with defer
+// to recover a panic and handle it as error
+func (c *Config) recoverPanic(writer http.ResponseWriter, request *http.Request) {
+	recovered := recover()
+	if recovered == nil {
+		return
+	}
+	if buffered, ok := writer.(*BufferedResponseWriter); ok && !buffered.Discard() {
+		if err, ok := recovered.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+			panic(http.ErrAbortHandler)
+		}
+		abortResponse(httperr.NewPanicError(recovered, request), buffered.Status(), request)
+	}
+	if c.ErrorHandler == nil {
+		httperr.HandlePanic(recovered, writer, request)
+		return
+	}
+	if err, ok := recovered.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+		panic(http.ErrAbortHandler)
+	}
+	c.ErrorHandler.HandleError(httperr.NewPanicError(recovered, request), writer, request)
+}
+
+func (c *Config) encoders() []Encoder {
+	if c.Encoders == nil {
+		return Encoders
+	}
+	return c.Encoders
+}
+
+func (c *Config) templates() *Templates {
+	if c.Templates == nil {
+		return DefaultTemplates
+	}
+	return c.Templates
+}
+
+// Encode encodes response with encoder,
+// pretty-printed if PrettyPrint is true and
+// the encoder supports indentation.
+func (c *Config) Encode(encoder Encoder, response any) ([]byte, error) {
+	if c.PrettyPrint && encoder.EncodeIndent != nil {
+		return encoder.EncodeIndent(response, c.PrettyPrintIndent)
+	}
+	return encoder.Encode(response)
+}
+
+type boundHandler struct {
+	config  *Config
+	handler http.Handler
+}
+
+func (b boundHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	b.handler.ServeHTTP(writer, WithConfig(request, b.config))
+}
+
+func (b boundHandler) Unwrap() http.Handler {
+	return b.handler
+}
@@ -0,0 +1,101 @@
+package respond
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ungerik/go-httpx/httperr"
+)
+
+func ExampleConfig_Bind() {
+	compact := DefaultConfig()
+	compact.PrettyPrint = false
+	compact.PrettyPrintParam = "pretty"
+
+	handler := compact.Bind(JSON(func(w http.ResponseWriter, r *http.Request) (any, error) {
+		return map[string]int{"answer": 42}, nil
+	}))
+
+	for _, url := range []string{"/", "/?pretty=1"} {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, url, nil))
+		fmt.Println(recorder.Body.String())
+	}
+
+	// Output:
+	// {"answer":42}
+	// {
+	//   "answer": 42
+	// }
+}
+
+func TestRequestConfigPrettyPrintParam(t *testing.T) {
+	config := DefaultConfig()
+	config.PrettyPrint = false
+	config.PrettyPrintParam = "pretty"
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"/", false},
+		{"/?pretty", true},
+		{"/?pretty=", true},
+		{"/?pretty=1", true},
+		{"/?pretty=true", true},
+		{"/?pretty=0", false},
+		{"/?pretty=false", false},
+		{"/?pretty=invalid", false},
+		{"/?other=1", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			request := WithConfig(httptest.NewRequest(http.MethodGet, tt.url, nil), config)
+			got := RequestConfig(request)
+			if got.PrettyPrint != tt.want {
+				t.Errorf("PrettyPrint = %t, want %t", got.PrettyPrint, tt.want)
+			}
+			if got.PrettyPrint && got.PrettyPrintIndent == "" {
+				t.Error("PrettyPrintIndent is empty")
+			}
+		})
+	}
+	if config.PrettyPrint {
+		t.Error("RequestConfig modified the attached Config")
+	}
+}
+
+func TestConfigBind(t *testing.T) {
+	catching := DefaultConfig()
+	catching.CatchPanics = true
+	panicking := JSON(func(w http.ResponseWriter, r *http.Request) (any, error) {
+		panic("bug")
+	})
+	tests := []struct {
+		name    string
+		handler http.Handler
+		want    int
+	}{
+		{"bound CatchPanics", catching.Bind(panicking), http.StatusInternalServerError},
+		{"bound ErrorHandler", (&Config{ErrorHandler: httperr.HandlerFunc(func(err error, w http.ResponseWriter, r *http.Request) bool {
+			w.WriteHeader(http.StatusTeapot)
+			return true
+		})}).Bind(JSON(func(w http.ResponseWriter, r *http.Request) (any, error) {
+			return nil, errors.New("failed")
+		})), http.StatusTeapot},
+		{"unbound", JSON(func(w http.ResponseWriter, r *http.Request) (any, error) {
+			return "ok", nil
+		}), http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			tt.handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+			if recorder.Code != tt.want {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,307 @@
+package respond
+
+import (
+	"bufio"
+	"context"
+	"encoding"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/ungerik/go-httpx/contenttype"
+)
+
+// CSVRows is an iterator over CSV rows that calls yield for every row
+// and returns the first error returned by yield.
+// Return it from CSV or TSV handler functions to stream rows
+// without holding them in memory.
+type CSVRows func(yield func(row []string) error) error
+
+// CSV is a handler type for functions that return rows
+// to be written as comma-separated values with Content-Type text/csv.
+//
+// Supported row types are:
+//   - [][]string: written unchanged
+//   - CSVRows: an iterator over rows that are written as they are yielded
+//   - slices of structs or struct pointers: a header row with the names
+//     of the `csv` struct tags up to the first comma, or the field names
+//     for untagged fields, followed by one row per element
+//     with the columns in field order.
+//     Fields tagged with "-" are ignored and embedded structs are flattened.
+//
+// The rows are streamed with encoding/csv using the CSVDelimiter
+// and CSVWithBOM settings of the request's Config.
+// Return Attachment to add a Content-Disposition header with a filename
+// or a Result to control the status code and headers.
+//
+// Errors returned by the handler function or a CSVRows iterator
+// before the first bytes of the body were written are handled by httperr.Handle.
+// Later errors are logged and abort the response like for JSONStream,
+// unless the response is still buffered because BufferResponses is true.
+//
+// Example:
+//
+//	http.Handle("/reports/users.csv", respond.CSV(func(w http.ResponseWriter, r *http.Request) (any, error) {
+//	    users, err := db.GetUsers()
+//	    if err != nil {
+//	        return nil, err
+//	    }
+//	    return respond.Attachment("users.csv", users), nil
+//	}))
+type CSV func(http.ResponseWriter, *http.Request) (rows any, err error)
+
+// ServeHTTP implements http.Handler for CSV.
+// It calls the handler function, handles any error, and writes the rows as CSV.
+// If CatchPanics of the request's Config is true, panics are recovered and handled as errors.
+func (handlerFunc CSV) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	config := RequestConfig(request)
+	writer, commit := config.bufferResponse(writer)
+	defer commit()
+	if config.CatchPanics {
+		defer config.recoverPanic(writer, request)
+	}
+
+	rows, err := handlerFunc(writer, request)
+	if config.HandleError(err, writer, request) {
+		return
+	}
+
+	config.WriteCSV(writer, request, rows)
+}
+
+// TSV is a handler type for functions that return rows to be written
+// as tab-separated values with Content-Type text/tab-separated-values.
+// It supports the same row types and has the same semantics as CSV,
+// except that the delimiter is always a tab.
+//
+// Example:
+//
+//	http.Handle("/reports/users.tsv", respond.TSV(func(w http.ResponseWriter, r *http.Request) (any, error) {
+//	    return db.GetUsers()
+//	}))
+type TSV func(http.ResponseWriter, *http.Request) (rows any, err error)
+
+// ServeHTTP implements http.Handler for TSV.
+// It calls the handler function, handles any error, and writes the rows as TSV.
+// If CatchPanics of the request's Config is true, panics are recovered and handled as errors.
+func (handlerFunc TSV) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	config := RequestConfig(request)
+	writer, commit := config.bufferResponse(writer)
+	defer commit()
+	if config.CatchPanics {
+		defer config.recoverPanic(writer, request)
+	}
+
+	rows, err := handlerFunc(writer, request)
+	if config.HandleError(err, writer, request) {
+		return
+	}
+
+	config.WriteTSV(writer, request, rows)
+}
+
+// WriteCSV writes rows as CSV using the Config of the request.
+// See CSV for the supported row types and error handling.
+func WriteCSV(writer http.ResponseWriter, request *http.Request, rows any) {
+	RequestConfig(request).WriteCSV(writer, request, rows)
+}
+
+// WriteTSV writes rows as TSV using the Config of the request.
+// See CSV for the supported row types and error handling.
+func WriteTSV(writer http.ResponseWriter, request *http.Request, rows any) {
+	RequestConfig(request).WriteTSV(writer, request, rows)
+}
+
+// WriteCSV writes rows as CSV with the CSVDelimiter of the Config.
+// See CSV for the supported row types and error handling.
+func (c *Config) WriteCSV(writer http.ResponseWriter, request *http.Request, rows any) {
+	delimiter := c.CSVDelimiter
+	if delimiter == 0 {
+		delimiter = ','
+	}
+	c.writeCSV(writer, request, contenttype.CSV, delimiter, rows)
+}
+
+// WriteTSV writes rows as TSV.
+// See CSV for the supported row types and error handling.
+func (c *Config) WriteTSV(writer http.ResponseWriter, request *http.Request, rows any) {
+	c.writeCSV(writer, request, contenttype.TSV, '\t', rows)
+}
+
+func (c *Config) writeCSV(writer http.ResponseWriter, request *http.Request, contentType string, delimiter rune, rows any) {
+	rows, statusCode, writeBody := c.applyResult(writer, request, rows)
+	if !writeBody {
+		return
+	}
+	writer.Header().Set("Content-Type", contentType)
+	if isConditional(request, statusCode) && c.handlePreconditions(writer, request) {
+		return
+	}
+	ctx := context.Background()
+	if request != nil {
+		ctx = request.Context()
+	}
+	deferred := &deferredStatusWriter{ResponseWriter: writer, statusCode: statusCode}
+	err := encodeCSV(ctx, deferred, delimiter, c.CSVWithBOM, rows)
+	switch {
+	case err == nil:
+		deferred.writeHeader()
+	case !deferred.wroteHeader || discardResponse(writer):
+		writer.Header().Del("Content-Disposition")
+		c.HandleError(err, writer, request)
+	case ctx.Err() == nil:
+		abortResponse(err, deferred.status(), request)
+	}
+}
+
+func encodeCSV(ctx context.Context, writer *deferredStatusWriter, delimiter rune, bom bool, rows any) error {
+	buf := bufio.NewWriter(writer)
+	if bom {
+		buf.WriteString("\uFEFF") //#nosec G104
+	}
+	w := csv.NewWriter(buf)
+	w.Comma = delimiter
+
+	if iterator, ok := rows.(func(yield func(row []string) error) error); ok {
+		rows = CSVRows(iterator)
+	}
+	var err error
+	switch x := rows.(type) {
+	case nil:
+	case [][]string:
+		for _, row := range x {
+			if err = w.Write(row); err != nil {
+				break
+			}
+		}
+	case CSVRows:
+		err = x(func(row []string) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			return w.Write(row)
+		})
+	default:
+		err = writeCSVStructs(w, rows)
+	}
+	if err != nil {
+		return err
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func writeCSVStructs(w *csv.Writer, rows any) error {
+	v := reflect.ValueOf(rows)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return fmt.Errorf("can't write %T as CSV", rows)
+	}
+	elemType := v.Type().Elem()
+	if elemType.Kind() == reflect.Pointer {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		return fmt.Errorf("can't write %T as CSV", rows)
+	}
+
+	columns := csvColumns(elemType, nil, nil)
+	row := make([]string, len(columns))
+	for i, column := range columns {
+		row[i] = column.name
+	}
+	if err := w.Write(row); err != nil {
+		return err
+	}
+	for i := 0; i < v.Len(); i++ {
+		elem := v.Index(i)
+		if elem.Kind() == reflect.Pointer {
+			if elem.IsNil() {
+				continue
+			}
+			elem = elem.Elem()
+		}
+		for col, column := range columns {
+			value, err := csvValue(elem.FieldByIndex(column.index))
+			if err != nil {
+				return fmt.Errorf("can't write CSV column %q: %w", column.name, err)
+			}
+			row[col] = value
+		}
+		if err := w.Write(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type csvColumn struct {
+	name  string
+	index []int
+}
+
+// csvColumns returns the columns of the struct type t
+// in field order, flattening embedded structs
+func csvColumns(t reflect.Type, index []int, columns []csvColumn) []csvColumn {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag := field.Tag.Get("csv")
+		if tag == "-" {
+			continue
+		}
+		name, tagged := field.Name, false
+		if tagName, _, _ := strings.Cut(tag, ","); tagName != "" {
+			name, tagged = tagName, true
+		}
+		fieldIndex := append(append([]int(nil), index...), i)
+		if field.Anonymous && !tagged && field.Type.Kind() == reflect.Struct && !reflect.PointerTo(field.Type).Implements(typeOfTextMarshaler) {
+			columns = csvColumns(field.Type, fieldIndex, columns)
+			continue
+		}
+		columns = append(columns, csvColumn{name: name, index: fieldIndex})
+	}
+	return columns
+}
+
+var typeOfTextMarshaler = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+func csvValue(v reflect.Value) (string, error) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return "", nil
+		}
+		v = v.Elem()
+	}
+	if v.Type().Implements(typeOfTextMarshaler) {
+		text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(text), err
+	}
+	if reflect.PointerTo(v.Type()).Implements(typeOfTextMarshaler) {
+		ptr := reflect.New(v.Type())
+		ptr.Elem().Set(v)
+		text, err := ptr.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(text), err
+	}
+	if stringer, ok := v.Interface().(fmt.Stringer); ok {
+		return stringer.String(), nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, v.Type().Bits()), nil
+	default:
+		return fmt.Sprint(v.Interface()), nil
+	}
+}
@@ -0,0 +1,207 @@
+package respond
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ungerik/go-httpx/httperr"
+)
+
+func ExampleCSV() {
+	type User struct {
+		Name    string    `csv:"name"`
+		Born    time.Time `csv:"born"`
+		Score   float64   `csv:"score"`
+		private string
+		Secret  string `csv:"-"`
+	}
+	handler := CSV(func(w http.ResponseWriter, r *http.Request) (any, error) {
+		users := []User{
+			{Name: "Alice", Born: time.Date(1990, 1, 2, 0, 0, 0, 0, time.UTC), Score: 9.5},
+			{Name: "Bob, Jr.", Born: time.Date(2001, 3, 4, 0, 0, 0, 0, time.UTC), Score: 7},
+		}
+		return Attachment("users 2026.csv", users), nil
+	})
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/users.csv", nil))
+
+	fmt.Println(recorder.Header().Get("Content-Type"))
+	fmt.Println(recorder.Header().Get("Content-Disposition"))
+	fmt.Print(recorder.Body.String())
+
+	// Output:
+	// text/csv; charset=utf-8
+	// attachment; filename="users 2026.csv"
+	// name,born,score
+	// Alice,1990-01-02T00:00:00Z,9.5
+	// "Bob, Jr.",2001-03-04T00:00:00Z,7
+}
+
+type csvLevel int
+
+func (l csvLevel) MarshalText() ([]byte, error) {
+	if l < 0 {
+		return nil, errors.New("negative level")
+	}
+	return []byte(fmt.Sprintf("L%d", l)), nil
+}
+
+func TestCSV(t *testing.T) {
+	type Base struct {
+		ID int `csv:"id"`
+	}
+	type Row struct {
+		Base
+		Name  *string `csv:"name"`
+		Level csvLevel
+		Any   any `csv:"any"`
+	}
+	name := "Alice"
+	tests := []struct {
+		name       string
+		rows       any
+		delimiter  rune
+		bom        bool
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "no rows", rows: [][]string{}, wantStatus: 200, wantBody: ""},
+		{name: "string rows with quoting", rows: [][]string{{"a", "b,c"}, {"line\nbreak", `q"uote`}}, wantStatus: 200, wantBody: "a,\"b,c\"\n\"line\nbreak\",\"q\"\"uote\"\n"},
+		{name: "custom delimiter", rows: [][]string{{"a", "b;c"}}, delimiter: ';', wantStatus: 200, wantBody: "a;\"b;c\"\n"},
+		{name: "BOM", rows: [][]string{{"a"}}, bom: true, wantStatus: 200, wantBody: "\uFEFFa\n"},
+		{name: "empty struct slice writes header", rows: []Row{}, wantStatus: 200, wantBody: "id,name,Level,any\n"},
+		{
+			name: "tag options are not part of the header",
+			rows: []struct {
+				A string `csv:"a,omitempty"`
+				B string `csv:",omitempty"`
+				C string `csv:"-,"`
+			}{{"1", "2", "3"}},
+			wantStatus: 200,
+			wantBody:   "a,B,-\n1,2,3\n",
+		},
+		{
+			name:       "embedded, pointer, marshaler, and interface fields",
+			rows:       []*Row{{Base: Base{ID: 1}, Name: &name, Level: 2, Any: 1.5}, nil, {Base: Base{ID: 2}}},
+			wantStatus: 200,
+			wantBody:   "id,name,Level,any\n1,Alice,L2,1.5\n2,,L0,\n",
+		},
+		{
+			name: "plain iterator function",
+			rows: func(yield func([]string) error) error {
+				return yield([]string{"x"})
+			},
+			wantStatus: 200,
+			wantBody:   "x\n",
+		},
+		{name: "unsupported type", rows: "a,b", wantStatus: 500},
+		{name: "slice of non-structs", rows: []int{1, 2}, wantStatus: 500},
+		{name: "marshaler error", rows: []Row{{Level: -1}}, wantStatus: 500},
+		{
+			name: "iterator error before first write",
+			rows: CSVRows(func(yield func([]string) error) error {
+				return httperr.NotFound
+			}),
+			wantStatus: 404,
+		},
+		{
+			name: "buffered iterator error is handled",
+			rows: CSVRows(func(yield func([]string) error) error {
+				if err := yield([]string{"x"}); err != nil {
+					return err
+				}
+				return httperr.Forbidden
+			}),
+			wantStatus: 403,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.CSVDelimiter = tt.delimiter
+			config.CSVWithBOM = tt.bom
+			config.BufferResponses = true
+			handler := CSV(func(w http.ResponseWriter, r *http.Request) (any, error) {
+				return Attachment("rows.csv", tt.rows), nil
+			})
+			recorder := httptest.NewRecorder()
+			config.Bind(handler).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", recorder.Code, tt.wantStatus, recorder.Body)
+			}
+			if tt.wantStatus != 200 {
+				if got := recorder.Header().Get("Content-Disposition"); got != "" {
+					t.Errorf("error response has Content-Disposition %q", got)
+				}
+				return
+			}
+			if got := recorder.Header().Get("Content-Type"); got != "text/csv; charset=utf-8" {
+				t.Errorf("Content-Type = %q", got)
+			}
+			if got := recorder.Body.String(); got != tt.wantBody {
+				t.Errorf("body = %q, want %q", got, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestTSV(t *testing.T) {
+	tests := []struct {
+		name      string
+		delimiter rune
+		rows      any
+		wantBody  string
+	}{
+		{"tabs", 0, [][]string{{"a", "b c"}, {"1", "2"}}, "a\tb c\n1\t2\n"},
+		{"ignores CSVDelimiter", ';', [][]string{{"a;b", "c"}}, "a;b\tc\n"},
+		{"quotes tabs", 0, [][]string{{"a\tb"}}, "\"a\tb\"\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.CSVDelimiter = tt.delimiter
+			handler := TSV(func(w http.ResponseWriter, r *http.Request) (any, error) {
+				return tt.rows, nil
+			})
+			recorder := httptest.NewRecorder()
+			config.Bind(handler).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+			if got := recorder.Header().Get("Content-Type"); got != "text/tab-separated-values; charset=utf-8" {
+				t.Errorf("Content-Type = %q", got)
+			}
+			if got := recorder.Body.String(); got != tt.wantBody {
+				t.Errorf("body = %q, want %q", got, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestWriteCSVWithoutRequest(t *testing.T) {
+	tests := []struct {
+		name       string
+		rows       any
+		wantStatus int
+		wantBody   string
+	}{
+		{"rows", [][]string{{"a", "b"}}, 200, "a,b\n"},
+		{"iterator", CSVRows(func(yield func([]string) error) error { return yield([]string{"x"}) }), 200, "x\n"},
+		{"Result", Created("/x", [][]string{{"a"}}), 201, "a\n"},
+		{"unsupported type", 1, 500, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			WriteCSV(recorder, nil, tt.rows)
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == 200 && recorder.Body.String() != tt.wantBody {
+				t.Errorf("body = %q, want %q", recorder.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
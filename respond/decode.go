@@ -0,0 +1,149 @@
+package respond
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/ungerik/go-httpx/contenttype"
+	"github.com/ungerik/go-httpx/httperr"
+)
+
+// DecodeRequestBody decodes the body of the request into target
+// according to the Content-Type header of the request:
+//   - application/json and */*+json as JSON
+//   - application/xml, text/xml and */*+xml as XML
+//   - application/x-www-form-urlencoded as form using DecodeForm
+//   - multipart/form-data as multipart form using DecodeForm
+//
+// A request without Content-Type header is decoded as JSON.
+// The body is limited to MaxRequestBodySize bytes using http.MaxBytesReader.
+// If DisallowUnknownFields is true, then JSON objects and forms
+// with keys that don't match a field of target are rejected.
+//
+// The returned errors are httperr responses:
+// 400 Bad Request for an empty or malformed body
+// or data after the JSON value,
+// 413 Request Entity Too Large if the body exceeds MaxRequestBodySize,
+// and 415 Unsupported Media Type for other content types.
+// The messages of 400 responses don't expose the errors of the decoders.
+// A target that is not a non-nil pointer or a form field
+// of an unsupported type results in 500 Internal Server Error.
+//
+// The settings of the Config returned by RequestConfig are used.
+func DecodeRequestBody(writer http.ResponseWriter, request *http.Request, target any) error {
+	return RequestConfig(request).DecodeRequestBody(writer, request, target)
+}
+
+// DecodeRequestBody decodes the body of the request into target
+// like the package level DecodeRequestBody function
+// but using the MaxRequestBodySize, MultipartMaxMemory,
+// and DisallowUnknownFields settings of the Config.
+func (c *Config) DecodeRequestBody(writer http.ResponseWriter, request *http.Request, target any) error {
+	if v := reflect.ValueOf(target); v.Kind() != reflect.Pointer || v.IsNil() {
+		return decodeError(fmt.Errorf("%w: can't decode request body into non-pointer %T", errDecodeTarget, target))
+	}
+	if request.Body == nil {
+		request.Body = http.NoBody
+	}
+	if c.MaxRequestBodySize > 0 {
+		request.Body = http.MaxBytesReader(writer, request.Body, c.MaxRequestBodySize)
+	}
+
+	var err error
+	mediaType := contenttype.MediaType(request.Header.Get("Content-Type"))
+	switch {
+	case mediaType == "" || mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		decoder := json.NewDecoder(request.Body)
+		if c.DisallowUnknownFields {
+			decoder.DisallowUnknownFields()
+		}
+		err = decoder.Decode(target)
+		if err == nil {
+			err = decodeJSONEnd(decoder)
+		}
+
+	case mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml"):
+		err = xml.NewDecoder(request.Body).Decode(target)
+
+	case mediaType == contenttype.WWWFormURLEncoded:
+		err = request.ParseForm()
+		if err == nil {
+			err = decodeForm(request.PostForm, nil, target, c.DisallowUnknownFields)
+		}
+
+	case mediaType == contenttype.MultipartFormData:
+		err = request.ParseMultipartForm(c.MultipartMaxMemory)
+		if err == nil {
+			err = decodeForm(request.MultipartForm.Value, request.MultipartForm.File, target, c.DisallowUnknownFields)
+		}
+
+	default:
+		return httperr.WithHeader(
+			httperr.Errorf(http.StatusUnsupportedMediaType, "Unsupported Content-Type %q", mediaType),
+			"Accept-Post",
+			strings.Join([]string{"application/json", "application/xml", contenttype.WWWFormURLEncoded, contenttype.MultipartFormData}, ", "),
+		)
+	}
+	return decodeError(err)
+}
+
+// decodeJSONEnd returns an error if there is
+// more than white space after the decoded JSON value
+func decodeJSONEnd(decoder *json.Decoder) error {
+	var trailing json.RawMessage
+	switch err := decoder.Decode(&trailing); {
+	case err == io.EOF:
+		return nil
+	case err != nil:
+		return err
+	default:
+		return errors.New("json: invalid data after top-level value")
+	}
+}
+
+// errDecodeTarget is wrapped by errors of
+// targets that values can't be decoded into
+var errDecodeTarget = errors.New("invalid decode target")
+
+// decodeError converts an error from decoding a
+// request body into a httperr response
+func decodeError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return httperr.WithStatus(err, http.StatusRequestEntityTooLarge, fmt.Sprintf("Request body larger than %d bytes", maxBytesErr.Limit))
+	}
+	if errors.Is(err, io.EOF) {
+		return httperr.WithStatus(err, http.StatusBadRequest, "Request body is empty")
+	}
+	var response httperr.Response
+	if errors.As(err, &response) {
+		return err
+	}
+	if errors.Is(err, errDecodeTarget) {
+		return httperr.WithStatus(err, http.StatusInternalServerError)
+	}
+	var fieldErr *formFieldError
+	if errors.As(err, &fieldErr) {
+		if fieldErr.err == nil {
+			return httperr.WithStatus(err, http.StatusBadRequest, fmt.Sprintf("Unknown form field %q", fieldErr.name))
+		}
+		return httperr.WithStatus(err, http.StatusBadRequest, fmt.Sprintf("Invalid form field %q", fieldErr.name))
+	}
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return httperr.WithStatus(err, http.StatusBadRequest, fmt.Sprintf("Invalid value for field %q", typeErr.Field))
+	}
+	if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		return httperr.WithStatus(err, http.StatusBadRequest, "Unknown field "+field)
+	}
+	return httperr.WithStatus(err, http.StatusBadRequest, "Malformed request body")
+}
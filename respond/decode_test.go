@@ -0,0 +1,184 @@
+package respond
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ungerik/go-httpx/contenttype"
+	"github.com/ungerik/go-httpx/httperr"
+)
+
+func ExampleJSONIn() {
+	type Greeting struct {
+		Name  string `json:"name"`
+		Times int    `json:"times"`
+	}
+	handler := JSONIn[Greeting, string](func(w http.ResponseWriter, r *http.Request, in Greeting) (string, error) {
+		return strings.Repeat("Hello "+in.Name+"! ", in.Times), nil
+	})
+
+	requests := []struct{ contentType, body string }{
+		{contenttype.JSON, `{"name":"Alice","times":2}`},
+		{contenttype.WWWFormURLEncoded, `name=Bob&times=1`},
+		{contenttype.JSON, `{"name":`},
+		{contenttype.PlainText, `Alice`},
+	}
+	for _, req := range requests {
+		request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(req.body))
+		request.Header.Set("Content-Type", req.contentType)
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		fmt.Println(recorder.Code, strings.TrimSpace(recorder.Body.String()))
+	}
+
+	// Output:
+	// 200 "Hello Alice! Hello Alice! "
+	// 200 "Hello Bob! "
+	// 400 Malformed request body
+	// 415 Unsupported Content-Type "text/plain"
+}
+
+func TestDecodeRequestBody(t *testing.T) {
+	type Input struct {
+		Name  string   `json:"name" xml:"name"`
+		Times int      `json:"times" xml:"times"`
+		IPs   []net.IP `json:"ips" xml:"ips"`
+	}
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        Input
+		wantStatus  int
+		wantMessage string
+	}{
+		{"json", contenttype.JSON, `{"name":"Alice","times":2}`, Input{Name: "Alice", Times: 2}, 0, ""},
+		{"no content type", "", `{"name":"Alice"}`, Input{Name: "Alice"}, 0, ""},
+		{"json suffix", "application/vnd.api+json", `{"name":"Alice"}`, Input{Name: "Alice"}, 0, ""},
+		{"xml", "application/xml", `<Input><name>Bob</name><times>3</times></Input>`, Input{Name: "Bob", Times: 3}, 0, ""},
+		{"form", contenttype.WWWFormURLEncoded, `name=Bob&times=1&ips=10.0.0.1&ips=::1`, Input{Name: "Bob", Times: 1, IPs: []net.IP{net.ParseIP("10.0.0.1"), net.ParseIP("::1")}}, 0, ""},
+		{"empty body", contenttype.JSON, ``, Input{}, http.StatusBadRequest, "Request body is empty"},
+		{"malformed json", contenttype.JSON, `{"name":`, Input{}, http.StatusBadRequest, "Malformed request body"},
+		{"json trailing garbage", contenttype.JSON, `{"name":"Alice"}garbage`, Input{}, http.StatusBadRequest, "Malformed request body"},
+		{"json second value", contenttype.JSON, `{"name":"Alice"} {"name":"Bob"}`, Input{}, http.StatusBadRequest, "Malformed request body"},
+		{"json trailing white space", contenttype.JSON, "{\"name\":\"Alice\"}\r\n ", Input{Name: "Alice"}, 0, ""},
+		{"json type mismatch", contenttype.JSON, `{"times":"two"}`, Input{}, http.StatusBadRequest, `Invalid value for field "times"`},
+		{"json unknown field", contenttype.JSON, `{"nick":"Al"}`, Input{}, http.StatusBadRequest, `Unknown field "nick"`},
+		{"malformed xml", "text/xml", `<Input><name>`, Input{}, http.StatusBadRequest, "Malformed request body"},
+		{"form unknown field", contenttype.WWWFormURLEncoded, `nick=Al`, Input{}, http.StatusBadRequest, `Unknown form field "nick"`},
+		{"form invalid int", contenttype.WWWFormURLEncoded, `times=two`, Input{}, http.StatusBadRequest, `Invalid form field "times"`},
+		{"form invalid ip", contenttype.WWWFormURLEncoded, `ips=10.0.0`, Input{}, http.StatusBadRequest, `Invalid form field "ips"`},
+		{"body too large", contenttype.JSON, `{"name":"` + strings.Repeat("x", 100) + `"}`, Input{}, http.StatusRequestEntityTooLarge, "Request body larger than 64 bytes"},
+		{"unsupported content type", contenttype.PlainText, `Alice`, Input{}, http.StatusUnsupportedMediaType, `Unsupported Content-Type "text/plain"`},
+	}
+	config := DefaultConfig()
+	config.MaxRequestBodySize = 64
+	config.DisallowUnknownFields = true
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				request.Header.Set("Content-Type", tt.contentType)
+			}
+			var got Input
+			err := config.DecodeRequestBody(httptest.NewRecorder(), request, &got)
+			if tt.wantStatus == 0 {
+				if err != nil {
+					t.Fatalf("DecodeRequestBody() error = %v", err)
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("DecodeRequestBody() decoded %#v, want %#v", got, tt.want)
+				}
+				return
+			}
+			if got := httperr.StatusCode(err); got != tt.wantStatus {
+				t.Errorf("StatusCode() = %d, want %d", got, tt.wantStatus)
+			}
+			recorder := httptest.NewRecorder()
+			httperr.Handle(err, recorder, request)
+			if body := strings.TrimSpace(recorder.Body.String()); body != tt.wantMessage {
+				t.Errorf("response body = %q, want %q", body, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestDecodeRequestBodyInvalidTarget(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		target      any
+	}{
+		{"json nil", contenttype.JSON, `{}`, nil},
+		{"json non-pointer", contenttype.JSON, `{}`, struct{}{}},
+		{"xml non-pointer", "application/xml", `<a/>`, struct{}{}},
+		{"form into string", contenttype.WWWFormURLEncoded, `a=b`, new(string)},
+		{"form unsupported field type", contenttype.WWWFormURLEncoded, `A=b`, &struct{ A chan int }{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			request.Header.Set("Content-Type", tt.contentType)
+			err := DecodeRequestBody(httptest.NewRecorder(), request, tt.target)
+			if got := httperr.StatusCode(err); got != http.StatusInternalServerError {
+				t.Errorf("StatusCode() = %d, want %d, error: %v", got, http.StatusInternalServerError, err)
+			}
+		})
+	}
+}
+
+func TestDecodeForm(t *testing.T) {
+	type Embedded struct {
+		Page int `form:"page"`
+	}
+	type Form struct {
+		Embedded
+		IP      net.IP    `form:"ip"`
+		IPs     []net.IP  `form:"ips"`
+		Tags    []string  `form:"tags"`
+		Agree   bool      `form:"agree"`
+		Limit   *uint8    `form:"limit"`
+		Time    time.Time `form:"time"`
+		Ignored string    `form:"-"`
+	}
+	limit := uint8(10)
+	tests := []struct {
+		name    string
+		values  url.Values
+		want    Form
+		wantErr bool
+	}{
+		{"net.IP", url.Values{"ip": {"10.0.0.1"}}, Form{IP: net.ParseIP("10.0.0.1")}, false},
+		{"[]net.IP", url.Values{"ips": {"10.0.0.1", "::1"}}, Form{IPs: []net.IP{net.ParseIP("10.0.0.1"), net.ParseIP("::1")}}, false},
+		{"slice", url.Values{"tags": {"a", "b"}}, Form{Tags: []string{"a", "b"}}, false},
+		{"checkbox", url.Values{"agree": {"on"}}, Form{Agree: true}, false},
+		{"pointer", url.Values{"limit": {"10"}}, Form{Limit: &limit}, false},
+		{"time", url.Values{"time": {"2024-01-02T03:04:05Z"}}, Form{Time: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}, false},
+		{"embedded", url.Values{"page": {"2"}}, Form{Embedded: Embedded{Page: 2}}, false},
+		{"ignored", url.Values{"Ignored": {"x"}}, Form{}, false},
+		{"invalid ip", url.Values{"ip": {"10.0.0"}}, Form{}, true},
+		{"uint overflow", url.Values{"limit": {"256"}}, Form{}, true},
+		{"invalid bool", url.Values{"agree": {"maybe"}}, Form{}, true},
+		{"invalid time", url.Values{"time": {"yesterday"}}, Form{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Form
+			err := DecodeForm(tt.values, nil, &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DecodeForm() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DecodeForm() decoded %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
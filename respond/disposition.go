@@ -0,0 +1,71 @@
+package respond
+
+import "strings"
+
+// ContentDisposition returns a Content-Disposition header value
+// for dispositionType like "attachment" or "inline" and filename
+// according to RFC 6266.
+//
+// Filenames with characters outside of printable ASCII are encoded
+// as filename* parameter according to RFC 5987, together with
+// a filename parameter with those characters replaced by '_'
+// for clients that don't support RFC 5987.
+// Path separators are replaced by '_' in both parameters.
+// No parameter is added if filename is empty.
+func ContentDisposition(dispositionType, filename string) string {
+	if filename == "" {
+		return dispositionType
+	}
+	filename = strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' {
+			return '_'
+		}
+		return r
+	}, filename)
+
+	var fallback strings.Builder
+	ascii := true
+	for _, r := range filename {
+		switch {
+		case r == '"' || r == '\\':
+			fallback.WriteByte('\\')
+			fallback.WriteRune(r)
+		case r < ' ' || r > '~':
+			fallback.WriteByte('_')
+			ascii = false
+		default:
+			fallback.WriteRune(r)
+		}
+	}
+	value := dispositionType + `; filename="` + fallback.String() + `"`
+	if ascii {
+		return value
+	}
+	return value + "; filename*=UTF-8''" + encodeRFC5987(filename)
+}
+
+// encodeRFC5987 percent-encodes all bytes of s
+// that are not an attr-char according to RFC 5987
+func encodeRFC5987(s string) string {
+	const hex = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if isRFC5987AttrChar(c) {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(hex[c>>4])
+		b.WriteByte(hex[c&0x0F])
+	}
+	return b.String()
+}
+
+func isRFC5987AttrChar(c byte) bool {
+	switch {
+	case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		return true
+	}
+	return strings.IndexByte("!#$&+-.^_`|~", c) >= 0
+}
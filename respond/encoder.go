@@ -0,0 +1,114 @@
+package respond
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"html/template"
+
+	"github.com/ungerik/go-httpx/contenttype"
+)
+
+// Encoder encodes response values into
+// a response body of a content type.
+type Encoder struct {
+	// ContentType of the encoded body
+	ContentType string
+	// Encode encodes a response value
+	Encode func(response any) ([]byte, error)
+	// EncodeIndent optionally encodes a response value
+	// pretty-printed with indent per indentation level.
+	// Encode is used for pretty-printing if EncodeIndent is nil.
+	EncodeIndent func(response any, indent string) ([]byte, error)
+}
+
+// Encoders are the encoders offered by the Negotiated handler
+// in the order of the server's preference
+// if the Config of the request has no Encoders.
+// The first encoder is used if the request has no Accept header.
+// Use RegisterEncoder to replace or add encoders.
+//
+// By default, the following encoders are registered:
+//   - application/json: like EncodeJSON
+//   - application/xml: like WriteXML with XML header
+//   - application/yaml: EncodeYAML
+//   - application/msgpack: EncodeMessagePack
+//   - application/cbor: EncodeCBOR
+//   - text/plain: EncodePlaintext
+//   - text/html: EncodeHTML
+var Encoders = []Encoder{
+	jsonEncoder,
+	xmlEncoder,
+	yamlEncoder,
+	messagePackEncoder,
+	cborEncoder,
+	{ContentType: contenttype.PlainText, Encode: EncodePlaintext},
+	{ContentType: contenttype.HTML, Encode: EncodeHTML},
+}
+
+// RegisterEncoder replaces the encoder for the media type
+// of encoder.ContentType in Encoders or appends it if there is
+// no encoder for the media type yet.
+//
+// RegisterEncoder modifies Encoders without synchronization,
+// so it must only be called during initialization,
+// like from an init function, before any requests are served.
+//
+// Example:
+//
+//	func init() {
+//	    respond.RegisterEncoder(respond.Encoder{
+//	        ContentType:  "application/toml",
+//	        Encode:       encodeTOML,
+//	        EncodeIndent: encodeTOMLIndent,
+//	    })
+//	}
+func RegisterEncoder(encoder Encoder) {
+	mediaType := contenttype.MediaType(encoder.ContentType)
+	for i := range Encoders {
+		if contenttype.MediaType(Encoders[i].ContentType) == mediaType {
+			Encoders[i] = encoder
+			return
+		}
+	}
+	Encoders = append(Encoders, encoder)
+}
+
+// EncodePlaintext encodes the response as plain text.
+// Strings, byte slices, fmt.Stringer and error values
+// are used as text, all other values are encoded with EncodeJSON.
+func EncodePlaintext(response any) ([]byte, error) {
+	switch x := response.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []byte(x), nil
+	case []byte:
+		return x, nil
+	case error:
+		return []byte(x.Error()), nil
+	case fmt.Stringer:
+		return []byte(x.String()), nil
+	default:
+		return EncodeJSON(response)
+	}
+}
+
+// EncodeHTML encodes the response as HTML.
+// Values of type template.HTML are used unchanged,
+// all other values are encoded with EncodePlaintext
+// and written HTML escaped as preformatted text.
+func EncodeHTML(response any) ([]byte, error) {
+	if h, ok := response.(template.HTML); ok {
+		return []byte(h), nil
+	}
+	text, err := EncodePlaintext(response)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	buf.WriteString("<pre>")
+	buf.WriteString(html.EscapeString(string(text)))
+	buf.WriteString("</pre>")
+	return buf.Bytes(), nil
+}
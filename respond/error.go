@@ -1,14 +1,12 @@
 package respond
 
-import (
-	"net/http"
-
-	"github.com/ungerik/go-httpx/httperr"
-)
+import "net/http"
 
 // Error is a handler type for functions that return only an error.
 // The function is responsible for writing the response if there's no error.
 // Any returned error is automatically handled by httperr.Handle.
+// If BufferResponses of the request's Config is true, then anything the
+// function wrote before returning an error is discarded, see BufferedResponseWriter.
 //
 // This is useful for handlers that perform actions without returning data:
 //
@@ -22,16 +20,18 @@ import (
 type Error func(http.ResponseWriter, *http.Request) error
 
 // ServeHTTP implements http.Handler for Error.
-// It calls the handler function and passes any error to httperr.Handle.
-// If CatchPanics is true, panics are recovered and handled as errors.
+// It calls the handler function and passes any error to the ErrorHandler
+// of the request's Config, which is httperr.Handle by default.
+// If CatchPanics of the request's Config is true, panics are recovered and handled as errors.
 func (handlerFunc Error) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
-	if CatchPanics {
-		defer func() {
-			httperr.HandlePanic(recover(), writer, request)
-		}()
+	config := RequestConfig(request)
+	writer, commit := config.bufferResponse(writer)
+	defer commit()
+	if config.CatchPanics {
+		defer config.recoverPanic(writer, request)
 	}
 
 	err := handlerFunc(writer, request)
 
-	httperr.Handle(err, writer, request)
+	config.HandleError(err, writer, request)
 }
@@ -0,0 +1,315 @@
+package respond
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ungerik/go-httpx/contenttype"
+)
+
+// Event is a server-sent event written by EventSender.Send.
+type Event struct {
+	// ID is the optional event ID that clients send back
+	// in the Last-Event-ID header when reconnecting
+	ID string
+	// Name is the optional event type, "message" is used by clients if empty
+	Name string
+	// Data is written unchanged if it is a string or []byte,
+	// else it is encoded as JSON.
+	// Multi-line data is written as multiple data fields.
+	Data any
+	// Retry optionally sets the reconnection time of the client
+	Retry time.Duration
+}
+
+// EventStream is a handler type for functions that push server-sent events
+// (text/event-stream) to the client using the passed EventSender
+// until the client disconnects or the server shuts down.
+//
+// Every event is flushed immediately. After the first event or comment
+// was sent, keep-alive comments are written in the interval
+// of EventStreamKeepAlive of the request's Config.
+// The write deadline of the connection is removed so that
+// the server's WriteTimeout doesn't end the stream.
+//
+// The context of the EventSender is canceled when the request context
+// is canceled or when the http.Server of the request starts shutting down,
+// for example by GracefulShutdownServerOnSignal from the httpx package.
+// The handler function should return when it is done.
+//
+// Errors returned before the first event was sent are handled
+// like by the JSON handler with a complete error response.
+// Errors after the first event are handled like by JSONStream
+// by logging them and aborting the response.
+// Errors after the context of the EventSender was canceled are ignored.
+//
+// Example:
+//
+//	http.Handle("/api/updates", respond.EventStream(func(w http.ResponseWriter, r *http.Request, events *respond.EventSender) error {
+//	    updates := dashboard.Subscribe(events.LastEventID())
+//	    defer updates.Close()
+//	    for {
+//	        select {
+//	        case <-events.Done():
+//	            return nil
+//	        case update := <-updates.C:
+//	            err := events.Send(respond.Event{ID: update.ID, Name: "update", Data: update})
+//	            if err != nil {
+//	                return err
+//	            }
+//	        }
+//	    }
+//	}))
+type EventStream func(writer http.ResponseWriter, request *http.Request, events *EventSender) error
+
+// ServeHTTP implements http.Handler for EventStream.
+// It calls the handler function with an EventSender,
+// writes keep-alive comments while the handler is running,
+// and handles any error depending on whether the stream has already started.
+// If CatchPanics of the request's Config is true, panics are recovered and handled as errors.
+func (handlerFunc EventStream) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	config := RequestConfig(request)
+	if config.CatchPanics {
+		defer config.recoverPanic(writer, request)
+	}
+
+	ctx, cancel := context.WithCancel(request.Context())
+	defer cancel()
+	if shutdown := serverShutdown(request); shutdown != nil {
+		go func() {
+			select {
+			case <-shutdown:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+	}
+
+	events := &EventSender{
+		writer:      writer,
+		ctx:         ctx,
+		lastEventID: request.Header.Get("Last-Event-ID"),
+	}
+	defer events.close()
+	if config.EventStreamKeepAlive > 0 {
+		go events.keepAlive(config.EventStreamKeepAlive)
+	}
+
+	err := handlerFunc(writer, request, events)
+	events.close()
+	switch {
+	case err == nil:
+		events.start()
+	case !events.started:
+		config.HandleError(err, writer, request)
+	case ctx.Err() == nil:
+		abortResponse(err, http.StatusOK, request)
+	}
+}
+
+// EventSender writes server-sent events for an EventStream handler.
+// Its methods are safe for concurrent use.
+type EventSender struct {
+	writer      http.ResponseWriter
+	ctx         context.Context
+	lastEventID string
+
+	mutex   sync.Mutex
+	started bool
+	closed  bool
+}
+
+// LastEventID returns the value of the Last-Event-ID request header
+// that clients send when reconnecting to resume the stream
+// after the event with that ID.
+// An empty string is returned for new connections.
+func (s *EventSender) LastEventID() string {
+	return s.lastEventID
+}
+
+// Context returns a context that is canceled when the request context
+// is canceled or when the server starts shutting down.
+func (s *EventSender) Context() context.Context {
+	return s.ctx
+}
+
+// Done returns a channel that is closed
+// when the context of the EventSender is canceled.
+func (s *EventSender) Done() <-chan struct{} {
+	return s.ctx.Done()
+}
+
+// Send writes and flushes the event.
+// It returns the error of the context if it was canceled,
+// an error if the event has an ID or name containing line breaks
+// or its Data can't be encoded, or the error from writing the event.
+func (s *EventSender) Send(event Event) error {
+	if strings.ContainsAny(event.ID, "\r\n\x00") {
+		return fmt.Errorf("invalid event ID %q", event.ID)
+	}
+	if strings.ContainsAny(event.Name, "\r\n") {
+		return fmt.Errorf("invalid event name %q", event.Name)
+	}
+	var buf bytes.Buffer
+	if event.ID != "" {
+		buf.WriteString("id: " + event.ID + "\n")
+	}
+	if event.Name != "" {
+		buf.WriteString("event: " + event.Name + "\n")
+	}
+	if event.Retry > 0 {
+		buf.WriteString("retry: " + strconv.FormatInt(event.Retry.Milliseconds(), 10) + "\n")
+	}
+	if event.Data != nil {
+		var data []byte
+		switch x := event.Data.(type) {
+		case string:
+			data = []byte(x)
+		case []byte:
+			data = x
+		default:
+			var err error
+			data, err = json.Marshal(event.Data)
+			if err != nil {
+				return fmt.Errorf("can't marshal event data: %w", err)
+			}
+		}
+		for _, line := range eventLines(string(data)) {
+			buf.WriteString("data: " + line + "\n")
+		}
+	}
+	buf.WriteByte('\n')
+	return s.write(buf.Bytes())
+}
+
+// SendData writes and flushes an event with data and without ID or name.
+func (s *EventSender) SendData(data any) error {
+	return s.Send(Event{Data: data})
+}
+
+// Comment writes and flushes a comment that is ignored by clients.
+func (s *EventSender) Comment(text string) error {
+	var buf bytes.Buffer
+	for _, line := range eventLines(text) {
+		buf.WriteString(": " + line + "\n")
+	}
+	buf.WriteByte('\n')
+	return s.write(buf.Bytes())
+}
+
+// eventLines splits s at CRLF, LF, and CR line breaks
+// that all end a field of a server-sent event
+func eventLines(s string) []string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.ReplaceAll(s, "\r", "\n")
+	return strings.Split(s, "\n")
+}
+
+func (s *EventSender) write(b []byte) error {
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.closed {
+		return context.Canceled
+	}
+	s.startLocked()
+	if _, err := s.writer.Write(b); err != nil {
+		return err
+	}
+	http.NewResponseController(s.writer).Flush() //#nosec G104
+	return nil
+}
+
+// start writes the headers of the stream if not done yet
+func (s *EventSender) start() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.startLocked()
+}
+
+func (s *EventSender) startLocked() {
+	if s.started {
+		return
+	}
+	s.started = true
+	header := s.writer.Header()
+	header.Set("Content-Type", contenttype.EventStream)
+	header.Set("Cache-Control", "no-cache")
+	header.Set("X-Accel-Buffering", "no")
+	controller := http.NewResponseController(s.writer)
+	controller.SetWriteDeadline(time.Time{}) //#nosec G104
+	s.writer.WriteHeader(http.StatusOK)
+	controller.Flush() //#nosec G104
+}
+
+// close prevents further writes, for example by keepAlive,
+// after the handler function returned
+func (s *EventSender) close() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.closed = true
+}
+
+func (s *EventSender) keepAlive(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			if s.writeKeepAlive() != nil {
+				return
+			}
+		}
+	}
+}
+
+// writeKeepAlive writes a keep-alive comment if the stream has started,
+// so that the handler function can still return an error
+// that is handled with a complete error response before that
+func (s *EventSender) writeKeepAlive() error {
+	s.mutex.Lock()
+	started := s.started
+	s.mutex.Unlock()
+	if !started {
+		return nil
+	}
+	return s.write([]byte(": keep-alive\n\n"))
+}
+
+// serverShutdowns maps every *http.Server that served
+// an EventStream to a channel that is closed on shutdown.
+// The entry of a server is deleted when it shuts down.
+var serverShutdowns sync.Map
+
+// serverShutdown returns a channel that is closed when
+// the http.Server of the request starts shutting down,
+// or nil if the request has no server in its context.
+func serverShutdown(request *http.Request) <-chan struct{} {
+	server, _ := request.Context().Value(http.ServerContextKey).(*http.Server)
+	if server == nil {
+		return nil
+	}
+	shutdown := make(chan struct{})
+	actual, loaded := serverShutdowns.LoadOrStore(server, shutdown)
+	if !loaded {
+		server.RegisterOnShutdown(func() {
+			serverShutdowns.Delete(server)
+			close(shutdown)
+		})
+	}
+	return actual.(chan struct{})
+}
@@ -0,0 +1,241 @@
+package respond
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ungerik/go-httpx/contenttype"
+	"github.com/ungerik/go-httpx/httperr"
+)
+
+func ExampleEventStream() {
+	handler := EventStream(func(w http.ResponseWriter, r *http.Request, events *EventSender) error {
+		err := events.Send(Event{ID: "2", Name: "update", Data: map[string]int{"count": 2}, Retry: 5 * time.Second})
+		if err != nil {
+			return err
+		}
+		return events.SendData("resumed after " + events.LastEventID())
+	})
+
+	request := httptest.NewRequest(http.MethodGet, "/updates", nil)
+	request.Header.Set("Last-Event-ID", "1")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	fmt.Println(recorder.Header().Get("Content-Type"))
+	fmt.Print(recorder.Body.String())
+
+	// Output:
+	// text/event-stream
+	// id: 2
+	// event: update
+	// retry: 5000
+	// data: {"count":2}
+	//
+	// data: resumed after 1
+}
+
+func TestEventSenderSend(t *testing.T) {
+	tests := []struct {
+		name    string
+		event   Event
+		want    string
+		wantErr bool
+	}{
+		{"empty", Event{}, "\n", false},
+		{"multi-line data", Event{Data: "a\nb"}, "data: a\ndata: b\n\n", false},
+		{"CRLF data", Event{Data: "a\r\nb"}, "data: a\ndata: b\n\n", false},
+		{"CR data", Event{Data: "a\rb\r"}, "data: a\ndata: b\ndata: \n\n", false},
+		{"CR injection", Event{Data: []byte("a\revent: evil")}, "data: a\ndata: event: evil\n\n", false},
+		{"empty data", Event{Data: ""}, "data: \n\n", false},
+		{"JSON data", Event{Data: []int{1, 2}}, "data: [1,2]\n\n", false},
+		{"all fields", Event{ID: "1", Name: "tick", Data: "x", Retry: time.Second}, "id: 1\nevent: tick\nretry: 1000\ndata: x\n\n", false},
+		{"ID with CR", Event{ID: "1\r2"}, "", true},
+		{"ID with NUL", Event{ID: "1\x002"}, "", true},
+		{"name with LF", Event{Name: "a\nb"}, "", true},
+		{"unmarshallable data", Event{Data: func() {}}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			events := &EventSender{writer: recorder, ctx: context.Background()}
+			err := events.Send(tt.event)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Send() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if got := recorder.Body.String(); got != tt.want {
+				t.Errorf("Send() wrote %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEventSenderComment(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"single line", "ping", ": ping\n\n"},
+		{"LF", "a\nb", ": a\n: b\n\n"},
+		{"CRLF", "a\r\nb", ": a\n: b\n\n"},
+		{"CR injection", "a\rdata: evil", ": a\n: data: evil\n\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			events := &EventSender{writer: recorder, ctx: context.Background()}
+			if err := events.Comment(tt.text); err != nil {
+				t.Fatalf("Comment() error = %v", err)
+			}
+			if got := recorder.Body.String(); got != tt.want {
+				t.Errorf("Comment() wrote %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEventStreamErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		handler    EventStream
+		wantStatus int
+		wantType   string
+	}{
+		{
+			name:       "error before first event",
+			handler:    func(w http.ResponseWriter, r *http.Request, events *EventSender) error { return httperr.NotFound },
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name: "error after first event",
+			handler: func(w http.ResponseWriter, r *http.Request, events *EventSender) error {
+				events.SendData("x") //#nosec G104
+				return errors.New("failed")
+			},
+			wantStatus: http.StatusOK,
+			wantType:   contenttype.EventStream,
+		},
+		{
+			name:       "no events",
+			handler:    func(w http.ResponseWriter, r *http.Request, events *EventSender) error { return nil },
+			wantStatus: http.StatusOK,
+			wantType:   contenttype.EventStream,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			func() {
+				defer func() {
+					if r := recover(); r != nil && r != http.ErrAbortHandler {
+						panic(r)
+					}
+				}()
+				tt.handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+			}()
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+			if tt.wantType != "" && recorder.Header().Get("Content-Type") != tt.wantType {
+				t.Errorf("Content-Type = %q, want %q", recorder.Header().Get("Content-Type"), tt.wantType)
+			}
+		})
+	}
+}
+
+func TestEventStreamKeepAlive(t *testing.T) {
+	const interval = time.Millisecond
+	tests := []struct {
+		name          string
+		handler       EventStream
+		wantStatus    int
+		wantKeepAlive bool
+	}{
+		{
+			name: "error after interval before first event",
+			handler: func(w http.ResponseWriter, r *http.Request, events *EventSender) error {
+				time.Sleep(20 * interval)
+				return httperr.NotFound
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name: "no keep-alive before first event",
+			handler: func(w http.ResponseWriter, r *http.Request, events *EventSender) error {
+				time.Sleep(20 * interval)
+				return events.SendData("x")
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "keep-alive after first event",
+			handler: func(w http.ResponseWriter, r *http.Request, events *EventSender) error {
+				if err := events.SendData("x"); err != nil {
+					return err
+				}
+				time.Sleep(20 * interval)
+				return nil
+			},
+			wantStatus:    http.StatusOK,
+			wantKeepAlive: true,
+		},
+	}
+	config := DefaultConfig()
+	config.EventStreamKeepAlive = interval
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			config.Bind(tt.handler).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+			body := recorder.Body.String()
+			if got := strings.Contains(body, ": keep-alive"); got != tt.wantKeepAlive {
+				t.Errorf("keep-alive in body = %t, want %t: %q", got, tt.wantKeepAlive, body)
+			}
+			if tt.wantKeepAlive && !strings.HasPrefix(body, "data: x\n\n") {
+				t.Errorf("body = %q, want to start with the event", body)
+			}
+		})
+	}
+}
+
+func TestEventStreamServerShutdown(t *testing.T) {
+	started := make(chan struct{})
+	server := httptest.NewUnstartedServer(EventStream(func(w http.ResponseWriter, r *http.Request, events *EventSender) error {
+		if err := events.Comment("started"); err != nil {
+			return err
+		}
+		close(started)
+		<-events.Done()
+		return nil
+	}))
+	server.Start()
+	defer server.Close()
+
+	response, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	<-started
+	if _, ok := serverShutdowns.Load(server.Config); !ok {
+		t.Fatal("server not registered in serverShutdowns")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := server.Config.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if _, ok := serverShutdowns.Load(server.Config); ok {
+		t.Error("server still registered in serverShutdowns after shutdown")
+	}
+}
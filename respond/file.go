@@ -0,0 +1,222 @@
+package respond
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ungerik/go-httpx/httperr"
+)
+
+// FileContent is the content and metadata of a file
+// or blob response written by the Blob and File handlers.
+type FileContent struct {
+	// Content is the body of the response.
+	// It is closed after the response was written
+	// if it implements io.Closer.
+	Content io.ReadSeeker
+	// Name is the filename used for the Content-Disposition header
+	// and to determine the Content-Type from its extension
+	Name string
+	// ModTime is the optional modification time used
+	// for the Last-Modified header and conditional requests
+	ModTime time.Time
+	// ContentType is used for the Content-Type header.
+	// If empty, it is determined by the extension of Name
+	// or by sniffing the content.
+	ContentType string
+	// ETag is the optional entity tag used for conditional requests and If-Range,
+	// it is quoted if it is not already quoted
+	ETag string
+	// Inline controls whether the Content-Disposition is "inline"
+	// to display the content in the browser instead of "attachment"
+	// to download it
+	Inline bool
+}
+
+// OpenFile opens the file at path and returns it as FileContent
+// with the base name of the path as Name and its modification time.
+// Errors like os.ErrNotExist are returned unchanged so that
+// httperr.Handle can map them with SentinelHandlers.
+// Directories result in a 403 Forbidden error.
+func OpenFile(path string) (*FileContent, error) {
+	file, err := os.Open(path) //#nosec G304
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close() //#nosec G104
+		return nil, err
+	}
+	if info.IsDir() {
+		file.Close() //#nosec G104
+		return nil, httperr.DontLog(httperr.Forbidden)
+	}
+	return &FileContent{
+		Content: file,
+		Name:    filepath.Base(path),
+		ModTime: info.ModTime(),
+	}, nil
+}
+
+// Blob is a handler type for functions that return a FileContent
+// with an io.ReadSeeker to be downloaded or displayed.
+//
+// The content is served with http.ServeContent, so single and multiple
+// byte ranges (multipart/byteranges), If-Range, and conditional requests
+// are supported. The Content-Disposition header is written with the
+// Name of the FileContent encoded according to RFC 6266 and RFC 5987.
+//
+// Errors returned by the handler function, like os.ErrNotExist
+// mapped by httperr.SentinelHandlers, and error responses like
+// 416 Range Not Satisfiable are handled by httperr.Handle.
+//
+// Example:
+//
+//	http.Handle("/invoices/{id}", respond.Blob(func(w http.ResponseWriter, r *http.Request) (*respond.FileContent, error) {
+//	    invoice, err := db.GetInvoice(r.PathValue("id"))
+//	    if err != nil {
+//	        return nil, err
+//	    }
+//	    return &respond.FileContent{
+//	        Content:     bytes.NewReader(invoice.PDF),
+//	        Name:        "Rechnung " + invoice.Number + ".pdf",
+//	        ModTime:     invoice.Created,
+//	        ContentType: contenttype.PDF,
+//	    }, nil
+//	}))
+type Blob func(http.ResponseWriter, *http.Request) (*FileContent, error)
+
+// ServeHTTP implements http.Handler for Blob.
+// It calls the handler function, handles any error, and serves the content.
+// If CatchPanics of the request's Config is true, panics are recovered and handled as errors.
+func (handlerFunc Blob) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	config := RequestConfig(request)
+	if config.CatchPanics {
+		defer config.recoverPanic(writer, request)
+	}
+
+	content, err := handlerFunc(writer, request)
+	if config.HandleError(err, writer, request) {
+		return
+	}
+
+	config.ServeFileContent(writer, request, content)
+}
+
+// File is a handler type for functions that return the path
+// of a file to be downloaded.
+// The file is opened with OpenFile and served like by Blob.
+// A file that does not exist results in 404 Not Found
+// via httperr.SentinelHandlers.
+//
+// Example:
+//
+//	http.Handle("/downloads/{name}", respond.File(func(w http.ResponseWriter, r *http.Request) (string, error) {
+//	    return filepath.Join(downloadDir, filepath.Base(r.PathValue("name"))), nil
+//	}))
+type File func(http.ResponseWriter, *http.Request) (path string, err error)
+
+// ServeHTTP implements http.Handler for File.
+// It calls the handler function, opens the file, handles any error, and serves the file.
+// If CatchPanics of the request's Config is true, panics are recovered and handled as errors.
+func (handlerFunc File) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	config := RequestConfig(request)
+	if config.CatchPanics {
+		defer config.recoverPanic(writer, request)
+	}
+
+	path, err := handlerFunc(writer, request)
+	if config.HandleError(err, writer, request) {
+		return
+	}
+	content, err := OpenFile(path)
+	if config.HandleError(err, writer, request) {
+		return
+	}
+
+	config.ServeFileContent(writer, request, content)
+}
+
+// ServeFileContent serves content using the Config of the request.
+// See Blob for details.
+func ServeFileContent(writer http.ResponseWriter, request *http.Request, content *FileContent) {
+	RequestConfig(request).ServeFileContent(writer, request, content)
+}
+
+// ServeFileContent serves content with http.ServeContent
+// and handles error responses with the ErrorHandler of the Config.
+// See Blob for details.
+func (c *Config) ServeFileContent(writer http.ResponseWriter, request *http.Request, content *FileContent) {
+	if content == nil || content.Content == nil {
+		c.HandleError(httperr.DontLog(httperr.NotFound), writer, request)
+		return
+	}
+	if closer, ok := content.Content.(io.Closer); ok {
+		defer closer.Close() //#nosec G104
+	}
+
+	header := writer.Header()
+	if content.ContentType != "" {
+		header.Set("Content-Type", content.ContentType)
+	}
+	if content.ETag != "" {
+		header.Set("ETag", quoteETag(content.ETag))
+	}
+	switch {
+	case !content.Inline:
+		header.Set("Content-Disposition", ContentDisposition("attachment", content.Name))
+	case content.Name != "":
+		header.Set("Content-Disposition", ContentDisposition("inline", content.Name))
+	}
+
+	w := &serveContentWriter{ResponseWriter: writer}
+	http.ServeContent(w, request, content.Name, content.ModTime, content.Content)
+	if w.errorStatusCode == 0 {
+		return
+	}
+	for _, key := range []string{"Content-Type", "Content-Disposition", "Content-Encoding", "Content-Length", "Cache-Control", "ETag", "Last-Modified", "X-Content-Type-Options"} {
+		header.Del(key)
+	}
+	message := strings.TrimSpace(w.errorMessage.String())
+	if w.errorStatusCode >= 500 {
+		c.HandleError(fmt.Errorf("can't serve %q: %s", content.Name, message), writer, request)
+		return
+	}
+	c.HandleError(httperr.DontLog(httperr.New(w.errorStatusCode, message)), writer, request)
+}
+
+// serveContentWriter captures error responses
+// written by http.ServeContent
+type serveContentWriter struct {
+	http.ResponseWriter
+	errorStatusCode int
+	errorMessage    bytes.Buffer
+}
+
+func (w *serveContentWriter) WriteHeader(statusCode int) {
+	if statusCode >= 400 {
+		w.errorStatusCode = statusCode
+		return
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *serveContentWriter) Write(b []byte) (int, error) {
+	if w.errorStatusCode != 0 {
+		return w.errorMessage.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap returns the wrapped http.ResponseWriter
+// for http.ResponseController
+func (w *serveContentWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,259 @@
+package respond
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func ExampleBlob() {
+	handler := Blob(func(w http.ResponseWriter, r *http.Request) (*FileContent, error) {
+		return &FileContent{
+			Content: strings.NewReader("0123456789"),
+			Name:    "Übersicht 2026.txt",
+			ModTime: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		}, nil
+	})
+
+	for _, ranges := range []string{"", "bytes=2-5", "bytes=20-"} {
+		request := httptest.NewRequest(http.MethodGet, "/download", nil)
+		if ranges != "" {
+			request.Header.Set("Range", ranges)
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		fmt.Println(recorder.Code, recorder.Header().Get("Content-Range"), recorder.Body.String())
+	}
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/download", nil))
+	fmt.Println(recorder.Header().Get("Content-Disposition"))
+
+	// Output:
+	// 200  0123456789
+	// 206 bytes 2-5/10 2345
+	// 416 bytes */10 invalid range: failed to overlap
+	//
+	// attachment; filename="_bersicht 2026.txt"; filename*=UTF-8''%C3%9Cbersicht%202026.txt
+}
+
+func TestContentDisposition(t *testing.T) {
+	tests := []struct {
+		name            string
+		dispositionType string
+		filename        string
+		want            string
+	}{
+		{"no filename", "attachment", "", "attachment"},
+		{"ASCII", "inline", "report.pdf", `inline; filename="report.pdf"`},
+		{"quotes and backslashes", "attachment", `a"b.txt`, `attachment; filename="a\"b.txt"`},
+		{"path separators", "attachment", `../etc\passwd`, `attachment; filename=".._etc_passwd"`},
+		{"control character", "attachment", "a\nb", "attachment; filename=\"a_b\"; filename*=UTF-8''a%0Ab"},
+		{"non-ASCII", "attachment", "€ 1.txt", "attachment; filename=\"_ 1.txt\"; filename*=UTF-8''%E2%82%AC%201.txt"},
+		{"RFC 5987 attr-chars", "attachment", "ä!#$&+-.^_`|~'", "attachment; filename=\"_!#$&+-.^_`|~'\"; filename*=UTF-8''%C3%A4!#$&+-.^_`|~%27"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ContentDisposition(tt.dispositionType, tt.filename); got != tt.want {
+				t.Errorf("ContentDisposition() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+type closeRecorder struct {
+	*strings.Reader
+	closed bool
+}
+
+func (r *closeRecorder) Close() error {
+	r.closed = true
+	return nil
+}
+
+func TestBlob(t *testing.T) {
+	modified := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name            string
+		content         *FileContent
+		header          http.Header
+		wantStatus      int
+		wantBody        string
+		wantType        string
+		wantDisposition string
+	}{
+		{
+			name:       "nil content",
+			content:    nil,
+			wantStatus: 404,
+		},
+		{
+			name:       "nil reader",
+			content:    &FileContent{Name: "a.txt"},
+			wantStatus: 404,
+		},
+		{
+			name:            "content type from name",
+			content:         &FileContent{Content: strings.NewReader("{}"), Name: "a.json"},
+			wantStatus:      200,
+			wantBody:        "{}",
+			wantType:        "application/json",
+			wantDisposition: `attachment; filename="a.json"`,
+		},
+		{
+			name:            "explicit content type and inline",
+			content:         &FileContent{Content: strings.NewReader("x"), Name: "a.json", ContentType: "text/plain", Inline: true},
+			wantStatus:      200,
+			wantBody:        "x",
+			wantType:        "text/plain",
+			wantDisposition: `inline; filename="a.json"`,
+		},
+		{
+			name:       "inline without name",
+			content:    &FileContent{Content: strings.NewReader("<p>hi</p>"), Inline: true},
+			wantStatus: 200,
+			wantBody:   "<p>hi</p>",
+			wantType:   "text/html; charset=utf-8",
+		},
+		{
+			name:            "suffix range",
+			content:         &FileContent{Content: strings.NewReader("0123456789"), Name: "a.txt"},
+			header:          http.Header{"Range": {"bytes=-3"}},
+			wantStatus:      206,
+			wantBody:        "789",
+			wantType:        "text/plain; charset=utf-8",
+			wantDisposition: `attachment; filename="a.txt"`,
+		},
+		{
+			name:            "If-Range with current ETag",
+			content:         &FileContent{Content: strings.NewReader("0123456789"), Name: "a.txt", ETag: "v1"},
+			header:          http.Header{"Range": {"bytes=0-1"}, "If-Range": {`"v1"`}},
+			wantStatus:      206,
+			wantBody:        "01",
+			wantType:        "text/plain; charset=utf-8",
+			wantDisposition: `attachment; filename="a.txt"`,
+		},
+		{
+			name:            "If-Range with old ETag",
+			content:         &FileContent{Content: strings.NewReader("0123456789"), Name: "a.txt", ETag: "v2"},
+			header:          http.Header{"Range": {"bytes=0-1"}, "If-Range": {`"v1"`}},
+			wantStatus:      200,
+			wantBody:        "0123456789",
+			wantType:        "text/plain; charset=utf-8",
+			wantDisposition: `attachment; filename="a.txt"`,
+		},
+		{
+			name:       "malformed range",
+			content:    &FileContent{Content: strings.NewReader("0123456789"), Name: "a.txt"},
+			header:     http.Header{"Range": {"bytes=5-2"}},
+			wantStatus: 416,
+		},
+		{
+			name:       "If-None-Match",
+			content:    &FileContent{Content: strings.NewReader("x"), Name: "a.txt", ETag: "v1"},
+			header:     http.Header{"If-None-Match": {`"v1"`}},
+			wantStatus: 304,
+		},
+		{
+			name:       "If-Modified-Since",
+			content:    &FileContent{Content: strings.NewReader("x"), Name: "a.txt", ModTime: modified},
+			header:     http.Header{"If-Modified-Since": {modified.Format(http.TimeFormat)}},
+			wantStatus: 304,
+		},
+		{
+			name:       "If-Match failed",
+			content:    &FileContent{Content: strings.NewReader("x"), Name: "a.txt", ETag: "v1"},
+			header:     http.Header{"If-Match": {`"v2"`}},
+			wantStatus: 412,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var closer *closeRecorder
+			if tt.content != nil {
+				if reader, ok := tt.content.Content.(*strings.Reader); ok {
+					closer = &closeRecorder{Reader: reader}
+					tt.content.Content = closer
+				}
+			}
+			handler := Blob(func(w http.ResponseWriter, r *http.Request) (*FileContent, error) {
+				return tt.content, nil
+			})
+			request := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != nil {
+				request.Header = tt.header
+			}
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+			if closer != nil && !closer.closed {
+				t.Errorf("content not closed")
+			}
+			if tt.wantStatus >= 400 {
+				if got := recorder.Header().Get("Content-Disposition"); got != "" {
+					t.Errorf("Content-Disposition = %q for status %d", got, tt.wantStatus)
+				}
+				return
+			}
+			if tt.wantStatus == http.StatusNotModified {
+				if recorder.Body.Len() > 0 {
+					t.Errorf("body = %q for status 304", recorder.Body)
+				}
+				return
+			}
+			if got := recorder.Body.String(); got != tt.wantBody {
+				t.Errorf("body = %q, want %q", got, tt.wantBody)
+			}
+			if got := recorder.Header().Get("Content-Type"); got != tt.wantType {
+				t.Errorf("Content-Type = %q, want %q", got, tt.wantType)
+			}
+			if got := recorder.Header().Get("Content-Disposition"); got != tt.wantDisposition {
+				t.Errorf("Content-Disposition = %q, want %q", got, tt.wantDisposition)
+			}
+		})
+	}
+}
+
+func TestFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("hello"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+		wantBody   string
+	}{
+		{"file", filepath.Join(dir, "a.txt"), 200, "hello"},
+		{"missing file", filepath.Join(dir, "missing.txt"), 404, ""},
+		{"directory", dir, 403, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := File(func(w http.ResponseWriter, r *http.Request) (string, error) {
+				return tt.path, nil
+			})
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+			if tt.wantStatus != 200 {
+				return
+			}
+			if got := recorder.Body.String(); got != tt.wantBody {
+				t.Errorf("body = %q, want %q", got, tt.wantBody)
+			}
+			if got := recorder.Header().Get("Last-Modified"); got == "" {
+				t.Errorf("missing Last-Modified")
+			}
+		})
+	}
+}
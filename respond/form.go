@@ -0,0 +1,241 @@
+package respond
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	typeOfTextUnmarshaler = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	typeOfFileHeader      = reflect.TypeOf((*multipart.FileHeader)(nil))
+	typeOfFileHeaders     = reflect.TypeOf([]*multipart.FileHeader(nil))
+	typeOfURLValues       = reflect.TypeOf(url.Values(nil))
+)
+
+// formFieldError is returned by DecodeForm for a form key
+// that doesn't match a field or a value that can't be parsed
+type formFieldError struct {
+	name string
+	err  error
+}
+
+func (e *formFieldError) Error() string {
+	if e.err == nil {
+		return fmt.Sprintf("unknown form field %q", e.name)
+	}
+	return fmt.Sprintf("invalid form field %q: %s", e.name, e.err)
+}
+
+func (e *formFieldError) Unwrap() error {
+	return e.err
+}
+
+// DecodeForm sets the fields of the struct pointed to by target
+// from form values and uploaded multipart files.
+//
+// Fields are matched by the name of their `form` struct tag,
+// else the name of their `json` struct tag, else the field name.
+// Fields tagged with "-" are ignored and embedded structs are flattened.
+// Supported field types are strings, bools, integers, floats,
+// types implementing encoding.TextUnmarshaler like time.Time,
+// pointers and slices of those, and *multipart.FileHeader
+// or []*multipart.FileHeader for files.
+//
+// If target points to url.Values or map[string]string,
+// then all values are copied into the map.
+// If DisallowUnknownFields is true, then form keys
+// that don't match any field result in an error.
+func DecodeForm(values url.Values, files map[string][]*multipart.FileHeader, target any) error {
+	return decodeForm(values, files, target, DisallowUnknownFields)
+}
+
+func decodeForm(values url.Values, files map[string][]*multipart.FileHeader, target any, disallowUnknownFields bool) error {
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return fmt.Errorf("%w: can't decode form into non-pointer %T", errDecodeTarget, target)
+	}
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+
+	switch {
+	case v.Type() == typeOfURLValues:
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		for key, vals := range values {
+			v.SetMapIndex(reflect.ValueOf(key), reflect.ValueOf(vals))
+		}
+		return nil
+
+	case v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String && v.Type().Elem().Kind() == reflect.String:
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		for key := range values {
+			v.SetMapIndex(reflect.ValueOf(key).Convert(v.Type().Key()), reflect.ValueOf(values.Get(key)).Convert(v.Type().Elem()))
+		}
+		return nil
+
+	case v.Kind() != reflect.Struct:
+		return fmt.Errorf("%w: can't decode form into %T", errDecodeTarget, target)
+	}
+
+	fields := make(map[string]reflect.Value)
+	formFields(v, fields)
+
+	if disallowUnknownFields {
+		var unknown []string
+		for key := range values {
+			if _, ok := fields[key]; !ok {
+				unknown = append(unknown, key)
+			}
+		}
+		for key := range files {
+			if _, ok := fields[key]; !ok {
+				unknown = append(unknown, key)
+			}
+		}
+		if len(unknown) > 0 {
+			sort.Strings(unknown)
+			return &formFieldError{name: unknown[0]}
+		}
+	}
+
+	for name, field := range fields {
+		switch field.Type() {
+		case typeOfFileHeader:
+			if fileHeaders := files[name]; len(fileHeaders) > 0 {
+				field.Set(reflect.ValueOf(fileHeaders[0]))
+			}
+			continue
+		case typeOfFileHeaders:
+			if fileHeaders := files[name]; len(fileHeaders) > 0 {
+				field.Set(reflect.ValueOf(fileHeaders))
+			}
+			continue
+		}
+		vals, ok := values[name]
+		if !ok || len(vals) == 0 {
+			continue
+		}
+		if err := setFormField(field, vals); err != nil {
+			if errors.Is(err, errDecodeTarget) {
+				return fmt.Errorf("form field %q: %w", name, err)
+			}
+			return &formFieldError{name: name, err: err}
+		}
+	}
+	return nil
+}
+
+// formFields collects the settable fields of the struct v
+// by their form name, flattening embedded structs
+func formFields(v reflect.Value, fields map[string]reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		structField := t.Field(i)
+		if !structField.IsExported() {
+			continue
+		}
+		name, tagged := formFieldName(structField)
+		if name == "-" {
+			continue
+		}
+		field := v.Field(i)
+		if structField.Anonymous && !tagged && field.Kind() == reflect.Struct && !reflect.PointerTo(field.Type()).Implements(typeOfTextUnmarshaler) {
+			formFields(field, fields)
+			continue
+		}
+		if _, exists := fields[name]; !exists {
+			fields[name] = field
+		}
+	}
+}
+
+func formFieldName(field reflect.StructField) (name string, tagged bool) {
+	for _, key := range []string{"form", "json"} {
+		tag, ok := field.Tag.Lookup(key)
+		if !ok {
+			continue
+		}
+		name, _, _ = strings.Cut(tag, ",")
+		if name != "" {
+			return name, true
+		}
+	}
+	return field.Name, false
+}
+
+func setFormField(field reflect.Value, vals []string) error {
+	if field.Kind() == reflect.Slice && !reflect.PointerTo(field.Type()).Implements(typeOfTextUnmarshaler) {
+		slice := reflect.MakeSlice(field.Type(), len(vals), len(vals))
+		for i, val := range vals {
+			if err := setFormValue(slice.Index(i), val); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+		return nil
+	}
+	return setFormValue(field, vals[0])
+}
+
+func setFormValue(v reflect.Value, val string) error {
+	if v.Kind() == reflect.Pointer {
+		ptr := reflect.New(v.Type().Elem())
+		if err := setFormValue(ptr.Elem(), val); err != nil {
+			return err
+		}
+		v.Set(ptr)
+		return nil
+	}
+	if v.CanAddr() && v.Addr().Type().Implements(typeOfTextUnmarshaler) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(val))
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(val)
+	case reflect.Bool:
+		if val == "on" {
+			v.SetBool(true)
+			return nil
+		}
+		b, err := strconv.ParseBool(val)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(val, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(val, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(val, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("%w: unsupported form field type %s", errDecodeTarget, v.Type())
+	}
+	return nil
+}
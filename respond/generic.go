@@ -0,0 +1,432 @@
+package respond
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// genericMap is a map with ordered string keys
+// used for structs and maps by genericValue
+type genericMap []genericMapEntry
+
+type genericMapEntry struct {
+	Key   string
+	Value any
+}
+
+// maxGenericDepth limits the nesting of values
+// to detect cyclic data structures
+const maxGenericDepth = 1000
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// genericValue converts value into a tree of nil, bool, int64, uint64,
+// float32, float64, string, []byte, []any, and genericMap values
+// that is written by the YAML, MessagePack, and CBOR encoders.
+//
+// Values are converted like encoding/json marshals them:
+// Struct fields are named by the struct tag with the key tag,
+// or by their json tag if they have no such tag,
+// respecting "-", omitempty, the string option, and embedded structs.
+// Types implementing json.Marshaler or encoding.TextMarshaler
+// are converted from their JSON or text representation
+// and map keys are sorted.
+// Unlike JSON, byte slices are kept as binary data.
+func genericValue(value any, tag string) (any, error) {
+	return convertGeneric(reflect.ValueOf(value), tag, 0)
+}
+
+func convertGeneric(v reflect.Value, tag string, depth int) (any, error) {
+	if depth > maxGenericDepth {
+		return nil, fmt.Errorf("value exceeds maximum nesting depth of %d", maxGenericDepth)
+	}
+	if !v.IsValid() {
+		return nil, nil
+	}
+	if (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) && v.IsNil() {
+		return nil, nil
+	}
+	if marshaler, ok := asInterface(v, jsonMarshalerType).(json.Marshaler); ok {
+		data, err := marshaler.MarshalJSON()
+		if err != nil {
+			return nil, err
+		}
+		return decodeGenericJSON(data)
+	}
+	if marshaler, ok := asInterface(v, textMarshalerType).(encoding.TextMarshaler); ok {
+		text, err := marshaler.MarshalText()
+		if err != nil {
+			return nil, err
+		}
+		return string(text), nil
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		return v.Bool(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint(), nil
+	case reflect.Float32:
+		return float32(v.Float()), nil
+	case reflect.Float64:
+		return v.Float(), nil
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Pointer, reflect.Interface:
+		return convertGeneric(v.Elem(), tag, depth+1)
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil, nil
+		}
+		if isByteSlice(v.Type()) {
+			return v.Bytes(), nil
+		}
+		fallthrough
+	case reflect.Array:
+		items := make([]any, v.Len())
+		for i := range items {
+			item, err := convertGeneric(v.Index(i), tag, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = item
+		}
+		return items, nil
+	case reflect.Map:
+		if v.IsNil() {
+			return nil, nil
+		}
+		m := make(genericMap, 0, v.Len())
+		for iter := v.MapRange(); iter.Next(); {
+			key, err := genericMapKey(iter.Key())
+			if err != nil {
+				return nil, err
+			}
+			value, err := convertGeneric(iter.Value(), tag, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			m = append(m, genericMapEntry{Key: key, Value: value})
+		}
+		sort.Slice(m, func(i, j int) bool { return m[i].Key < m[j].Key })
+		return m, nil
+	case reflect.Struct:
+		fields := genericStructFields(v.Type(), tag)
+		m := make(genericMap, 0, len(fields))
+		for _, field := range fields {
+			fieldValue, ok := fieldByIndex(v, field.index)
+			if !ok || field.omitEmpty && isEmptyValue(fieldValue) {
+				continue
+			}
+			var value any
+			var err error
+			if field.quoted {
+				value, err = quotedGenericValue(fieldValue, tag, depth+1)
+			} else {
+				value, err = convertGeneric(fieldValue, tag, depth+1)
+			}
+			if err != nil {
+				return nil, err
+			}
+			m = append(m, genericMapEntry{Key: field.name, Value: value})
+		}
+		return m, nil
+	}
+	return nil, &json.UnsupportedTypeError{Type: v.Type()}
+}
+
+// asInterface returns v or its address as value of the interface type iface
+// if it implements it, else nil
+func asInterface(v reflect.Value, iface reflect.Type) any {
+	if !v.CanInterface() {
+		return nil
+	}
+	if v.Type().Implements(iface) {
+		return v.Interface()
+	}
+	if v.Kind() != reflect.Pointer && v.CanAddr() && reflect.PointerTo(v.Type()).Implements(iface) {
+		return v.Addr().Interface()
+	}
+	return nil
+}
+
+// isByteSlice returns true for byte slice types
+// that encoding/json encodes as base64 string
+func isByteSlice(t reflect.Type) bool {
+	if t.Elem().Kind() != reflect.Uint8 {
+		return false
+	}
+	p := reflect.PointerTo(t.Elem())
+	return !p.Implements(jsonMarshalerType) && !p.Implements(textMarshalerType)
+}
+
+// quotedGenericValue returns the value of a field with the string option
+// as string like encoding/json, other values are converted unchanged
+func quotedGenericValue(v reflect.Value, tag string, depth int) (any, error) {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil, nil
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()), nil
+	case reflect.String:
+		quoted, err := json.Marshal(v.String())
+		return string(quoted), err
+	}
+	return convertGeneric(v, tag, depth)
+}
+
+// genericMapKey returns the string used for a map key like encoding/json
+func genericMapKey(key reflect.Value) (string, error) {
+	if key.Kind() == reflect.String {
+		return key.String(), nil
+	}
+	if marshaler, ok := asInterface(key, textMarshalerType).(encoding.TextMarshaler); ok {
+		if key.Kind() == reflect.Pointer && key.IsNil() {
+			return "", nil
+		}
+		text, err := marshaler.MarshalText()
+		return string(text), err
+	}
+	switch key.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(key.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(key.Uint(), 10), nil
+	}
+	return "", &json.UnsupportedTypeError{Type: key.Type()}
+}
+
+// fieldByIndex returns the nested field of v with index
+// or false if an embedded struct pointer on the way is nil
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// isEmptyValue returns true for values omitted by the omitempty option
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Pointer:
+		return v.IsNil()
+	}
+	return false
+}
+
+type genericField struct {
+	name      string
+	index     []int
+	tagged    bool
+	omitEmpty bool
+	quoted    bool
+}
+
+type genericFieldsKey struct {
+	typ reflect.Type
+	tag string
+}
+
+var genericFieldsCache sync.Map // genericFieldsKey -> []genericField
+
+// genericStructFields returns the encoded fields of the struct type t
+// in the order of their declaration following the rules of encoding/json
+// for embedded structs and conflicting names
+func genericStructFields(t reflect.Type, tag string) []genericField {
+	key := genericFieldsKey{typ: t, tag: tag}
+	if cached, ok := genericFieldsCache.Load(key); ok {
+		return cached.([]genericField)
+	}
+
+	type embedded struct {
+		typ   reflect.Type
+		index []int
+	}
+	var (
+		fields  []genericField
+		next    = []embedded{{typ: t}}
+		visited = make(map[reflect.Type]bool)
+	)
+	for len(next) > 0 {
+		current := next
+		next = nil
+		for _, e := range current {
+			if visited[e.typ] {
+				continue
+			}
+			visited[e.typ] = true
+			for i := 0; i < e.typ.NumField(); i++ {
+				sf := e.typ.Field(i)
+				fieldType := sf.Type
+				if fieldType.Name() == "" && fieldType.Kind() == reflect.Pointer {
+					fieldType = fieldType.Elem()
+				}
+				if !sf.IsExported() && (!sf.Anonymous || fieldType.Kind() != reflect.Struct) {
+					continue
+				}
+				tagValue, ok := sf.Tag.Lookup(tag)
+				if !ok {
+					tagValue = sf.Tag.Get("json")
+				}
+				if tagValue == "-" {
+					continue
+				}
+				name, options, _ := strings.Cut(tagValue, ",")
+				index := append(e.index[:len(e.index):len(e.index)], i)
+				if name == "" && sf.Anonymous && fieldType.Kind() == reflect.Struct {
+					next = append(next, embedded{typ: fieldType, index: index})
+					continue
+				}
+				if !sf.IsExported() {
+					continue
+				}
+				field := genericField{name: name, index: index, tagged: name != ""}
+				if name == "" {
+					field.name = sf.Name
+				}
+				for _, option := range strings.Split(options, ",") {
+					switch option {
+					case "omitempty":
+						field.omitEmpty = true
+					case "string":
+						field.quoted = true
+					}
+				}
+				fields = append(fields, field)
+			}
+		}
+	}
+
+	// Of fields with the same name, the shallowest one wins
+	// if it is the only one at its depth or the only tagged one,
+	// else all of them are omitted
+	sort.SliceStable(fields, func(i, j int) bool {
+		a, b := fields[i], fields[j]
+		if a.name != b.name {
+			return a.name < b.name
+		}
+		if len(a.index) != len(b.index) {
+			return len(a.index) < len(b.index)
+		}
+		return a.tagged && !b.tagged
+	})
+	dominant := fields[:0]
+	for i := 0; i < len(fields); {
+		j := i + 1
+		for j < len(fields) && fields[j].name == fields[i].name {
+			j++
+		}
+		group := fields[i:j]
+		if len(group) == 1 || len(group[0].index) < len(group[1].index) || group[0].tagged && !group[1].tagged {
+			dominant = append(dominant, group[0])
+		}
+		i = j
+	}
+	sort.Slice(dominant, func(i, j int) bool {
+		a, b := dominant[i].index, dominant[j].index
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return len(a) < len(b)
+	})
+
+	cached, _ := genericFieldsCache.LoadOrStore(key, dominant)
+	return cached.([]genericField)
+}
+
+// decodeGenericJSON decodes JSON data like the output
+// of a json.Marshaler into a generic value keeping
+// the order of object keys
+func decodeGenericJSON(data []byte) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return readGenericJSON(decoder)
+}
+
+func readGenericJSON(decoder *json.Decoder) (any, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t := token.(type) {
+	case json.Delim:
+		switch t {
+		case '[':
+			items := []any{}
+			for decoder.More() {
+				item, err := readGenericJSON(decoder)
+				if err != nil {
+					return nil, err
+				}
+				items = append(items, item)
+			}
+			_, err = decoder.Token()
+			return items, err
+		case '{':
+			m := genericMap{}
+			for decoder.More() {
+				key, err := decoder.Token()
+				if err != nil {
+					return nil, err
+				}
+				value, err := readGenericJSON(decoder)
+				if err != nil {
+					return nil, err
+				}
+				m = append(m, genericMapEntry{Key: key.(string), Value: value})
+			}
+			_, err = decoder.Token()
+			return m, err
+		}
+		return nil, fmt.Errorf("unexpected JSON delimiter %s", t)
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			return i, nil
+		}
+		if u, err := strconv.ParseUint(t.String(), 10, 64); err == nil {
+			return u, nil
+		}
+		return t.Float64()
+	}
+	return token, nil
+}
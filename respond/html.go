@@ -4,13 +4,17 @@ import (
 	"net/http"
 
 	"github.com/ungerik/go-httpx/contenttype"
-	"github.com/ungerik/go-httpx/httperr"
 )
 
 // HTML is a handler type for functions that return HTML content as bytes.
 // The returned HTML is automatically written with Content-Type: text/html.
 // Any error is handled by httperr.Handle.
 //
+// The handler function can set response headers and call WriteHeader
+// to use a different status code than 200 OK, the status code is
+// written together with the returned HTML. An empty response with
+// 204 No Content or 304 Not Modified is written without Content-Type.
+//
 // Example:
 //
 //	http.Handle("/page", respond.HTML(func(w http.ResponseWriter, r *http.Request) ([]byte, error) {
@@ -21,20 +25,21 @@ type HTML func(http.ResponseWriter, *http.Request) ([]byte, error)
 
 // ServeHTTP implements http.Handler for HTML.
 // It calls the handler function, handles any error, and writes the HTML response.
-// If CatchPanics is true, panics are recovered and handled as errors.
+// If CatchPanics of the request's Config is true, panics are recovered and handled as errors.
 func (handlerFunc HTML) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
-	if CatchPanics {
-		defer func() {
-			httperr.HandlePanic(recover(), writer, request)
-		}()
+	config := RequestConfig(request)
+	writer, commit := config.bufferResponse(writer)
+	defer commit()
+	if config.CatchPanics {
+		defer config.recoverPanic(writer, request)
 	}
 
-	response, err := handlerFunc(writer, request)
-	if httperr.Handle(err, writer, request) {
+	deferred := &deferredStatusWriter{ResponseWriter: writer}
+	response, err := handlerFunc(deferred, request)
+	if config.HandleError(err, writer, request) {
 		return
 	}
-
-	WriteHTML(writer, response)
+	config.writeDeferred(deferred, request, contenttype.HTML, response)
 }
 
 // StaticHTML is a handler type for serving static HTML content.
@@ -48,7 +53,8 @@ type StaticHTML string
 // ServeHTTP implements http.Handler for StaticHTML.
 // It writes the static HTML content on every request.
 func (s StaticHTML) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
-	WriteHTML(writer, []byte(s))
+	writer.Header().Add("Content-Type", contenttype.HTML)
+	RequestConfig(request).writeBody(writer, request, 0, []byte(s))
 }
 
 // WriteHTML writes the HTML response with the appropriate content type.
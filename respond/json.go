@@ -3,14 +3,18 @@ package respond
 import (
 	"encoding/json"
 	"net/http"
+	"reflect"
 
 	"github.com/ungerik/go-httpx/contenttype"
-	"github.com/ungerik/go-httpx/httperr"
 )
 
 // JSON is a handler type for functions that return data to be marshaled as JSON.
 // The returned data is automatically serialized to JSON and written with
 // Content-Type: application/json. Any error is handled by httperr.Handle.
+// Return a Result to control the status code and headers of the response.
+// Conditional GET and HEAD requests are answered with 304 Not Modified
+// if the Result has an ETag or LastModified validator
+// or if AutoETag of the request's Config is true.
 //
 // Example:
 //
@@ -22,40 +26,148 @@ type JSON func(http.ResponseWriter, *http.Request) (response any, err error)
 
 // ServeHTTP implements http.Handler for JSON.
 // It calls the handler function, handles any error, and marshals the response to JSON.
-// If CatchPanics is true, panics are recovered and handled as errors.
+// If CatchPanics of the request's Config is true, panics are recovered and handled as errors.
 func (handlerFunc JSON) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
-	if CatchPanics {
-		defer func() {
-			httperr.HandlePanic(recover(), writer, request)
-		}()
+	config := RequestConfig(request)
+	writer, commit := config.bufferResponse(writer)
+	defer commit()
+	if config.CatchPanics {
+		defer config.recoverPanic(writer, request)
 	}
 
 	response, err := handlerFunc(writer, request)
-	if httperr.Handle(err, writer, request) {
+	if config.HandleError(err, writer, request) {
 		return
 	}
 
-	WriteJSON(writer, response)
+	config.WriteJSON(writer, request, response)
+}
+
+// JSONOf is the generic counterpart of JSON for handler functions
+// that return a typed response to be marshaled as JSON.
+// It has the same error handling and panic recovery semantics as JSON
+// and implements TypedHandler to expose the response type.
+//
+// Example:
+//
+//	http.Handle("/api/users", respond.JSONOf[[]User](func(w http.ResponseWriter, r *http.Request) ([]User, error) {
+//	    return db.GetUsers()
+//	}))
+type JSONOf[T any] func(http.ResponseWriter, *http.Request) (response T, err error)
+
+// ServeHTTP implements http.Handler for JSONOf.
+// It calls the handler function, handles any error, and marshals the response to JSON.
+// If CatchPanics of the request's Config is true, panics are recovered and handled as errors.
+func (handlerFunc JSONOf[T]) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	config := RequestConfig(request)
+	writer, commit := config.bufferResponse(writer)
+	defer commit()
+	if config.CatchPanics {
+		defer config.recoverPanic(writer, request)
+	}
+
+	response, err := handlerFunc(writer, request)
+	if config.HandleError(err, writer, request) {
+		return
+	}
+
+	config.WriteJSON(writer, request, response)
+}
+
+// ResponseType returns the type T of the response.
+// It implements TypedHandler.
+func (JSONOf[T]) ResponseType() reflect.Type {
+	return typeOf[T]()
+}
+
+// JSONIn is a handler type for functions that receive the request body
+// decoded into a typed input and return a typed response to be marshaled as JSON.
+// The body is decoded with DecodeRequestBody according to its Content-Type,
+// so malformed bodies result in 400, too large bodies in 413,
+// and unsupported content types in 415 error responses
+// without calling the handler function.
+// It has the same error handling and panic recovery semantics as JSON
+// and implements TypedInputHandler.
+//
+// Example:
+//
+//	http.Handle("/api/users", respond.JSONIn[NewUser, *User](func(w http.ResponseWriter, r *http.Request, in NewUser) (*User, error) {
+//	    return db.CreateUser(in)
+//	}))
+type JSONIn[In, Out any] func(writer http.ResponseWriter, request *http.Request, input In) (response Out, err error)
+
+// ServeHTTP implements http.Handler for JSONIn.
+// It decodes the request body, calls the handler function,
+// handles any error, and marshals the response to JSON.
+// If CatchPanics of the request's Config is true, panics are recovered and handled as errors.
+func (handlerFunc JSONIn[In, Out]) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	config := RequestConfig(request)
+	writer, commit := config.bufferResponse(writer)
+	defer commit()
+	if config.CatchPanics {
+		defer config.recoverPanic(writer, request)
+	}
+
+	var input In
+	err := config.DecodeRequestBody(writer, request, &input)
+	if config.HandleError(err, writer, request) {
+		return
+	}
+
+	response, err := handlerFunc(writer, request, input)
+	if config.HandleError(err, writer, request) {
+		return
+	}
+
+	config.WriteJSON(writer, request, response)
+}
+
+// RequestType returns the type In of the decoded request body.
+// It implements TypedInputHandler.
+func (JSONIn[In, Out]) RequestType() reflect.Type {
+	return typeOf[In]()
+}
+
+// ResponseType returns the type Out of the response.
+// It implements TypedHandler.
+func (JSONIn[In, Out]) ResponseType() reflect.Type {
+	return typeOf[Out]()
 }
 
 // WriteJSON marshals the response to JSON and writes it with the appropriate content type.
 // If marshaling fails, an internal server error is written.
 // The response is pretty-printed if PrettyPrint is true.
 func WriteJSON(writer http.ResponseWriter, response any) {
-	b, err := EncodeJSON(response)
-	if err != nil {
-		httperr.WriteInternalServerError(err, writer)
-		return
-	}
-	writer.Header().Set("Content-Type", contenttype.JSON)
-	writer.Write(b) //#nosec G104
+	DefaultConfig().WriteJSON(writer, nil, response)
 }
 
 // EncodeJSON marshals the response to JSON bytes.
 // The response is pretty-printed if PrettyPrint is true.
 func EncodeJSON(response any) ([]byte, error) {
-	if PrettyPrint {
-		return json.MarshalIndent(response, "", PrettyPrintIndent)
-	}
-	return json.Marshal(response)
+	return DefaultConfig().EncodeJSON(response)
+}
+
+// WriteJSON marshals the response to JSON and writes it with the appropriate content type.
+// If marshaling fails, an internal server error is written.
+// The response is pretty-printed if PrettyPrint of the Config is true.
+// If response is a Result, then its status code and headers
+// are written and its body is marshaled.
+// If request is not nil, then the conditional headers of GET and HEAD
+// requests are evaluated and an ETag is added if AutoETag is true.
+func (c *Config) WriteJSON(writer http.ResponseWriter, request *http.Request, response any) {
+	c.WriteEncoded(writer, request, jsonEncoder, response)
+}
+
+// EncodeJSON marshals the response to JSON bytes.
+// The response is pretty-printed if PrettyPrint of the Config is true.
+func (c *Config) EncodeJSON(response any) ([]byte, error) {
+	return c.Encode(jsonEncoder, response)
+}
+
+var jsonEncoder = Encoder{
+	ContentType: contenttype.JSON,
+	Encode:      json.Marshal,
+	EncodeIndent: func(response any, indent string) ([]byte, error) {
+		return json.MarshalIndent(response, "", indent)
+	},
 }
@@ -0,0 +1,231 @@
+package respond
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ungerik/go-httpx/contenttype"
+	"github.com/ungerik/go-httpx/httperr"
+)
+
+func ExampleJSONOf() {
+	type User struct {
+		Name string `json:"name"`
+	}
+	handler := JSONOf[*User](func(w http.ResponseWriter, r *http.Request) (*User, error) {
+		return &User{Name: "Alice"}, nil
+	})
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	fmt.Println(handler.ResponseType())
+	fmt.Println(recorder.Body.String())
+
+	// Output:
+	// *respond.User
+	// {
+	//   "name": "Alice"
+	// }
+}
+
+func TestTypedHandlerTypes(t *testing.T) {
+	type User struct {
+		Name string `json:"name" xml:"name"`
+	}
+	tests := []struct {
+		name         string
+		handler      http.Handler
+		wantResponse reflect.Type
+		wantRequest  reflect.Type
+	}{
+		{"JSONOf struct pointer", JSONOf[*User](nil), reflect.TypeOf((*User)(nil)), nil},
+		{"JSONOf slice", JSONOf[[]User](nil), reflect.TypeOf([]User(nil)), nil},
+		{"JSONOf interface", JSONOf[any](nil), reflect.TypeOf((*any)(nil)).Elem(), nil},
+		{"JSONOf error interface", JSONOf[error](nil), reflect.TypeOf((*error)(nil)).Elem(), nil},
+		{"XMLOf struct", XMLOf[User](nil), reflect.TypeOf(User{}), nil},
+		{"JSONIn", JSONIn[User, *User](nil), reflect.TypeOf((*User)(nil)), reflect.TypeOf(User{})},
+		{"XMLIn", XMLIn[map[string]string, []string](nil), reflect.TypeOf([]string(nil)), reflect.TypeOf(map[string]string(nil))},
+		{"untyped JSON", JSON(nil), nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			typed, ok := tt.handler.(TypedHandler)
+			if ok != (tt.wantResponse != nil) {
+				t.Fatalf("implements TypedHandler = %t", ok)
+			}
+			if !ok {
+				return
+			}
+			if got := typed.ResponseType(); got != tt.wantResponse {
+				t.Errorf("ResponseType() = %v, want %v", got, tt.wantResponse)
+			}
+			input, ok := tt.handler.(TypedInputHandler)
+			if ok != (tt.wantRequest != nil) {
+				t.Fatalf("implements TypedInputHandler = %t", ok)
+			}
+			if ok && input.RequestType() != tt.wantRequest {
+				t.Errorf("RequestType() = %v, want %v", input.RequestType(), tt.wantRequest)
+			}
+		})
+	}
+}
+
+func TestJSONOf(t *testing.T) {
+	type User struct {
+		Name string `json:"name"`
+	}
+	tests := []struct {
+		name       string
+		handler    JSONOf[*User]
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "value",
+			handler:    func(http.ResponseWriter, *http.Request) (*User, error) { return &User{Name: "Bob"}, nil },
+			wantStatus: http.StatusOK,
+			wantBody:   "{\n  \"name\": \"Bob\"\n}",
+		},
+		{
+			name:       "nil pointer",
+			handler:    func(http.ResponseWriter, *http.Request) (*User, error) { return nil, nil },
+			wantStatus: http.StatusOK,
+			wantBody:   "null",
+		},
+		{
+			name:       "error ignores response",
+			handler:    func(http.ResponseWriter, *http.Request) (*User, error) { return &User{Name: "Bob"}, httperr.NotFound },
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "plain error",
+			handler:    func(http.ResponseWriter, *http.Request) (*User, error) { return nil, errors.New("db down") },
+			wantStatus: http.StatusInternalServerError,
+		},
+		{
+			name: "custom status",
+			handler: func(w http.ResponseWriter, r *http.Request) (*User, error) {
+				w.WriteHeader(http.StatusAccepted)
+				return &User{}, nil
+			},
+			wantStatus: http.StatusAccepted,
+			wantBody:   "{\n  \"name\": \"\"\n}",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			tt.handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+			if tt.wantStatus >= 400 {
+				if strings.Contains(recorder.Body.String(), "Bob") || strings.Contains(recorder.Body.String(), "db down") {
+					t.Errorf("error response leaks data: %q", recorder.Body.String())
+				}
+				return
+			}
+			if got := recorder.Header().Get("Content-Type"); got != "application/json; charset=utf-8" {
+				t.Errorf("Content-Type = %q", got)
+			}
+			if got := recorder.Body.String(); got != tt.wantBody {
+				t.Errorf("body = %q, want %q", got, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestXMLOf(t *testing.T) {
+	type User struct {
+		XMLName struct{} `xml:"user"`
+		Name    string   `xml:"name"`
+	}
+	tests := []struct {
+		name       string
+		handler    XMLOf[*User]
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "value",
+			handler:    func(http.ResponseWriter, *http.Request) (*User, error) { return &User{Name: "Bob"}, nil },
+			wantStatus: http.StatusOK,
+			wantBody:   "<name>Bob</name>",
+		},
+		{
+			name:       "error",
+			handler:    func(http.ResponseWriter, *http.Request) (*User, error) { return nil, httperr.Forbidden },
+			wantStatus: http.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			tt.handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+			if tt.wantBody == "" {
+				return
+			}
+			body, _ := io.ReadAll(recorder.Body)
+			if !strings.HasPrefix(recorder.Header().Get("Content-Type"), "application/xml") {
+				t.Errorf("Content-Type = %q", recorder.Header().Get("Content-Type"))
+			}
+			if !strings.Contains(string(body), tt.wantBody) {
+				t.Errorf("body = %q, want to contain %q", body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestWriteEncodedError(t *testing.T) {
+	defer func(logger httperr.ErrorLogger) { httperr.Logger = logger }(httperr.Logger)
+	httperr.Logger = nil
+	defer func(asProblem bool) { httperr.InternalServerErrorsAsProblem = asProblem }(httperr.InternalServerErrorsAsProblem)
+
+	unencodable := JSON(func(w http.ResponseWriter, r *http.Request) (any, error) {
+		return make(chan int), nil
+	})
+	teapot := &Config{ErrorHandler: httperr.HandlerFunc(func(err error, w http.ResponseWriter, r *http.Request) bool {
+		w.WriteHeader(http.StatusTeapot)
+		return true
+	})}
+	tests := []struct {
+		name       string
+		handler    http.Handler
+		accept     string
+		asProblem  bool
+		wantStatus int
+		wantType   string
+	}{
+		{"plain text", unencodable, "", false, http.StatusInternalServerError, "text/plain; charset=utf-8"},
+		{"Accept JSON", unencodable, "application/json", false, http.StatusInternalServerError, "application/json; charset=utf-8"},
+		{"as problem", unencodable, "application/json", true, http.StatusInternalServerError, contenttype.ProblemJSON},
+		{"Config ErrorHandler", teapot.Bind(unencodable), "", false, http.StatusTeapot, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httperr.InternalServerErrorsAsProblem = tt.asProblem
+			request := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.accept != "" {
+				request.Header.Set("Accept", tt.accept)
+			}
+			recorder := httptest.NewRecorder()
+			tt.handler.ServeHTTP(recorder, request)
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+			if tt.wantType != "" && recorder.Header().Get("Content-Type") != tt.wantType {
+				t.Errorf("Content-Type = %q, want %q", recorder.Header().Get("Content-Type"), tt.wantType)
+			}
+		})
+	}
+}
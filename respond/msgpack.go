@@ -0,0 +1,180 @@
+package respond
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"net/http"
+
+	"github.com/ungerik/go-httpx/contenttype"
+)
+
+// MessagePack is a handler type for functions that return data to be encoded as MessagePack.
+// The returned data is encoded with EncodeMessagePack and written with
+// Content-Type: application/msgpack. Any error is handled by httperr.Handle.
+// Return a Result to control the status code and headers of the response.
+//
+// Example:
+//
+//	http.Handle("/api/devices/{id}/state", respond.MessagePack(func(w http.ResponseWriter, r *http.Request) (any, error) {
+//	    return db.GetDeviceState(r.Context(), r.PathValue("id"))
+//	}))
+type MessagePack func(http.ResponseWriter, *http.Request) (response any, err error)
+
+// ServeHTTP implements http.Handler for MessagePack.
+// It calls the handler function, handles any error, and encodes the response as MessagePack.
+// If CatchPanics of the request's Config is true, panics are recovered and handled as errors.
+func (handlerFunc MessagePack) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	config := RequestConfig(request)
+	writer, commit := config.bufferResponse(writer)
+	defer commit()
+	if config.CatchPanics {
+		defer config.recoverPanic(writer, request)
+	}
+
+	response, err := handlerFunc(writer, request)
+	if config.HandleError(err, writer, request) {
+		return
+	}
+
+	config.WriteMessagePack(writer, request, response)
+}
+
+// WriteMessagePack encodes the response as MessagePack and writes it with the appropriate content type.
+// If encoding fails, an internal server error is written.
+func WriteMessagePack(writer http.ResponseWriter, response any) {
+	DefaultConfig().WriteMessagePack(writer, nil, response)
+}
+
+// EncodeMessagePack encodes the response as MessagePack
+// using the smallest representation of integers, strings,
+// binary data, arrays, and maps.
+//
+// Values are encoded like by encoding/json, so struct fields
+// are named by their msgpack tag or else by their json tag
+// and types implementing json.Marshaler or encoding.TextMarshaler
+// are encoded as their JSON or text representation.
+// Byte slices are encoded as binary data and structs and maps as maps with string keys.
+func EncodeMessagePack(response any) ([]byte, error) {
+	value, err := genericValue(response, "msgpack")
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	writeMessagePack(&buf, value)
+	return buf.Bytes(), nil
+}
+
+// WriteMessagePack encodes the response as MessagePack and writes it with the appropriate content type.
+// If encoding fails, an internal server error is written.
+// If response is a Result, then its status code and headers
+// are written and its body is encoded.
+// If request is not nil, then the conditional headers of GET and HEAD
+// requests are evaluated and an ETag is added if AutoETag is true.
+func (c *Config) WriteMessagePack(writer http.ResponseWriter, request *http.Request, response any) {
+	c.WriteEncoded(writer, request, messagePackEncoder, response)
+}
+
+var messagePackEncoder = Encoder{
+	ContentType: contenttype.MessagePack,
+	Encode:      EncodeMessagePack,
+}
+
+func writeMessagePack(buf *bytes.Buffer, value any) {
+	switch x := value.(type) {
+	case nil:
+		buf.WriteByte(0xc0)
+	case bool:
+		if x {
+			buf.WriteByte(0xc3)
+		} else {
+			buf.WriteByte(0xc2)
+		}
+	case int64:
+		writeMessagePackInt(buf, x)
+	case uint64:
+		writeMessagePackUint(buf, x)
+	case float32:
+		buf.WriteByte(0xca)
+		buf.Write(binary.BigEndian.AppendUint32(nil, math.Float32bits(x)))
+	case float64:
+		buf.WriteByte(0xcb)
+		buf.Write(binary.BigEndian.AppendUint64(nil, math.Float64bits(x)))
+	case string:
+		writeMessagePackHeader(buf, len(x), 0xa0, 32, 0xd9, 0xda, 0xdb)
+		buf.WriteString(x)
+	case []byte:
+		writeMessagePackHeader(buf, len(x), 0, 0, 0xc4, 0xc5, 0xc6)
+		buf.Write(x)
+	case []any:
+		writeMessagePackHeader(buf, len(x), 0x90, 16, 0, 0xdc, 0xdd)
+		for _, item := range x {
+			writeMessagePack(buf, item)
+		}
+	case genericMap:
+		writeMessagePackHeader(buf, len(x), 0x80, 16, 0, 0xde, 0xdf)
+		for _, entry := range x {
+			writeMessagePack(buf, entry.Key)
+			writeMessagePack(buf, entry.Value)
+		}
+	default:
+		writeMessagePack(buf, fmt.Sprint(x))
+	}
+}
+
+func writeMessagePackInt(buf *bytes.Buffer, i int64) {
+	switch {
+	case i >= 0:
+		writeMessagePackUint(buf, uint64(i))
+	case i >= -32:
+		buf.WriteByte(byte(i)) // negative fixint
+	case i >= math.MinInt8:
+		buf.Write([]byte{0xd0, byte(i)})
+	case i >= math.MinInt16:
+		buf.WriteByte(0xd1)
+		buf.Write(binary.BigEndian.AppendUint16(nil, uint16(i)))
+	case i >= math.MinInt32:
+		buf.WriteByte(0xd2)
+		buf.Write(binary.BigEndian.AppendUint32(nil, uint32(i)))
+	default:
+		buf.WriteByte(0xd3)
+		buf.Write(binary.BigEndian.AppendUint64(nil, uint64(i)))
+	}
+}
+
+func writeMessagePackUint(buf *bytes.Buffer, u uint64) {
+	switch {
+	case u <= 0x7f:
+		buf.WriteByte(byte(u)) // positive fixint
+	case u <= math.MaxUint8:
+		buf.Write([]byte{0xcc, byte(u)})
+	case u <= math.MaxUint16:
+		buf.WriteByte(0xcd)
+		buf.Write(binary.BigEndian.AppendUint16(nil, uint16(u)))
+	case u <= math.MaxUint32:
+		buf.WriteByte(0xce)
+		buf.Write(binary.BigEndian.AppendUint32(nil, uint32(u)))
+	default:
+		buf.WriteByte(0xcf)
+		buf.Write(binary.BigEndian.AppendUint64(nil, u))
+	}
+}
+
+// writeMessagePackHeader writes the type and length of a string, binary,
+// array, or map using the fix format with fixMax if fixMax is not zero
+// or the 8 bit length format if format8 is not zero
+func writeMessagePackHeader(buf *bytes.Buffer, length int, fix byte, fixMax int, format8, format16, format32 byte) {
+	switch {
+	case fixMax > 0 && length < fixMax:
+		buf.WriteByte(fix | byte(length))
+	case format8 != 0 && length <= math.MaxUint8:
+		buf.Write([]byte{format8, byte(length)})
+	case length <= math.MaxUint16:
+		buf.WriteByte(format16)
+		buf.Write(binary.BigEndian.AppendUint16(nil, uint16(length)))
+	default:
+		buf.WriteByte(format32)
+		buf.Write(binary.BigEndian.AppendUint32(nil, uint32(length)))
+	}
+}
@@ -0,0 +1,95 @@
+package respond
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
+
+func ExampleEncodeMessagePack() {
+	type Point struct {
+		X    int    `json:"x"`
+		Y    int    `json:"y" msgpack:"lat"`
+		Name string `json:"name,omitempty"`
+	}
+	encoded, err := EncodeMessagePack([]Point{{X: 1, Y: -1}, {X: 200, Y: -200, Name: "far"}})
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("% x\n", encoded)
+
+	// Output:
+	// 92 82 a1 78 01 a3 6c 61 74 ff 83 a1 78 cc c8 a3 6c 61 74 d1 ff 38 a4 6e 61 6d 65 a3 66 61 72
+}
+
+func TestEncodeMessagePack(t *testing.T) {
+	tests := []struct {
+		name       string
+		value      any
+		wantPrefix []byte
+		wantLen    int
+	}{
+		{"nil", nil, []byte{0xc0}, 1},
+		{"true", true, []byte{0xc3}, 1},
+		{"false", false, []byte{0xc2}, 1},
+		{"positive fixint", 127, []byte{0x7f}, 1},
+		{"uint8", 128, []byte{0xcc, 0x80}, 2},
+		{"uint16", 256, []byte{0xcd, 0x01, 0x00}, 3},
+		{"uint32", 1 << 16, []byte{0xce, 0x00, 0x01, 0x00, 0x00}, 5},
+		{"uint64", uint64(1) << 32, []byte{0xcf, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00}, 9},
+		{"max uint64", uint64(math.MaxUint64), []byte{0xcf, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, 9},
+		{"negative fixint -1", -1, []byte{0xff}, 1},
+		{"negative fixint -32", -32, []byte{0xe0}, 1},
+		{"int8 -33", -33, []byte{0xd0, 0xdf}, 2},
+		{"int8 -128", -128, []byte{0xd0, 0x80}, 2},
+		{"int16 -129", -129, []byte{0xd1, 0xff, 0x7f}, 3},
+		{"int32 -32769", -32769, []byte{0xd2, 0xff, 0xff, 0x7f, 0xff}, 5},
+		{"int64", int64(math.MinInt32) - 1, []byte{0xd3, 0xff, 0xff, 0xff, 0xff, 0x7f, 0xff, 0xff, 0xff}, 9},
+		{"min int64", int64(math.MinInt64), []byte{0xd3, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, 9},
+		{"float32", float32(1.5), []byte{0xca, 0x3f, 0xc0, 0x00, 0x00}, 5},
+		{"float64", 1.5, []byte{0xcb, 0x3f, 0xf8, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, 9},
+		{"empty string", "", []byte{0xa0}, 1},
+		{"fixstr 31", strings.Repeat("a", 31), []byte{0xbf, 'a'}, 32},
+		{"str8 32", strings.Repeat("a", 32), []byte{0xd9, 32, 'a'}, 34},
+		{"str8 255", strings.Repeat("a", 255), []byte{0xd9, 0xff, 'a'}, 257},
+		{"str16 256", strings.Repeat("a", 256), []byte{0xda, 0x01, 0x00, 'a'}, 259},
+		{"str32", strings.Repeat("a", 1<<16), []byte{0xdb, 0x00, 0x01, 0x00, 0x00, 'a'}, 5 + 1<<16},
+		{"empty bin", []byte{}, []byte{0xc4, 0x00}, 2},
+		{"bin8", []byte{1, 2}, []byte{0xc4, 0x02, 0x01, 0x02}, 4},
+		{"bin16", make([]byte, 256), []byte{0xc5, 0x01, 0x00, 0x00}, 259},
+		{"empty array", []int{}, []byte{0x90}, 1},
+		{"fixarray 15", make([]int, 15), []byte{0x9f, 0x00}, 16},
+		{"array16 16", make([]int, 16), []byte{0xdc, 0x00, 0x10, 0x00}, 19},
+		{"array32", make([]bool, 1<<16), []byte{0xdd, 0x00, 0x01, 0x00, 0x00, 0xc2}, 5 + 1<<16},
+		{"empty map", map[string]int{}, []byte{0x80}, 1},
+		{"fixmap", map[string]int{"a": 1}, []byte{0x81, 0xa1, 'a', 0x01}, 4},
+		{"nil slice", []int(nil), []byte{0xc0}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EncodeMessagePack(tt.value)
+			if err != nil {
+				t.Fatalf("EncodeMessagePack() error = %v", err)
+			}
+			if !bytes.HasPrefix(got, tt.wantPrefix) || len(got) != tt.wantLen {
+				t.Errorf("EncodeMessagePack() = % x (%d bytes), want prefix % x and %d bytes", got[:min(len(got), 16)], len(got), tt.wantPrefix, tt.wantLen)
+			}
+		})
+	}
+}
+
+func TestEncodeMessagePackMap16(t *testing.T) {
+	m := make(map[string]int, 16)
+	for i := 0; i < 16; i++ {
+		m[fmt.Sprintf("k%02d", i)] = i
+	}
+	got, err := EncodeMessagePack(m)
+	if err != nil {
+		t.Fatalf("EncodeMessagePack() error = %v", err)
+	}
+	if want := []byte{0xde, 0x00, 0x10, 0xa3, 'k', '0', '0', 0x00}; !bytes.HasPrefix(got, want) {
+		t.Errorf("EncodeMessagePack() = % x, want prefix % x", got[:8], want)
+	}
+}
@@ -0,0 +1,116 @@
+package respond
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/ungerik/go-httpx/contenttype"
+	"github.com/ungerik/go-httpx/httperr"
+)
+
+// Negotiated is a handler type for functions that return data to be
+// encoded in the format that best matches the Accept header of the request,
+// respecting q-values and wildcards, using the registered Encoders.
+// By default JSON, XML, YAML, MessagePack, CBOR, plain text, and HTML are offered.
+//
+// The Vary: Accept header is added to every response.
+// If none of the encoders is acceptable, then a 406 Not Acceptable
+// error is handled by httperr.Handle without calling the handler function.
+// Any error returned by the handler function is handled by httperr.Handle.
+//
+// Example:
+//
+//	http.Handle("/api/user", respond.Negotiated(func(w http.ResponseWriter, r *http.Request) (any, error) {
+//	    return db.GetUser(r)
+//	}))
+type Negotiated func(http.ResponseWriter, *http.Request) (response any, err error)
+
+// ServeHTTP implements http.Handler for Negotiated.
+// It negotiates the encoder, calls the handler function,
+// handles any error, and writes the encoded response.
+// If CatchPanics of the request's Config is true, panics are recovered and handled as errors.
+func (handlerFunc Negotiated) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	config := RequestConfig(request)
+	writer, commit := config.bufferResponse(writer)
+	defer commit()
+	if config.CatchPanics {
+		defer config.recoverPanic(writer, request)
+	}
+
+	encoder, err := config.NegotiateEncoder(writer, request)
+	if config.HandleError(err, writer, request) {
+		return
+	}
+
+	response, err := handlerFunc(writer, request)
+	if config.HandleError(err, writer, request) {
+		return
+	}
+
+	config.WriteEncoded(writer, request, encoder, response)
+}
+
+// NegotiateEncoder returns the encoder from Encoders
+// that best matches the Accept header of the request
+// and adds Accept to the Vary response header.
+// If none of the encoders is acceptable, then a
+// 406 Not Acceptable httperr.Response is returned.
+func NegotiateEncoder(writer http.ResponseWriter, request *http.Request) (Encoder, error) {
+	return RequestConfig(request).NegotiateEncoder(writer, request)
+}
+
+// WriteEncoded encodes the response with encoder and writes it
+// with the content type of the encoder.
+// If encoding fails, an internal server error is written.
+func WriteEncoded(writer http.ResponseWriter, encoder Encoder, response any) {
+	DefaultConfig().WriteEncoded(writer, nil, encoder, response)
+}
+
+// NegotiateEncoder returns the encoder from the Encoders of the Config
+// that best matches the Accept header of the request
+// and adds Accept to the Vary response header.
+// If none of the encoders is acceptable, then a
+// 406 Not Acceptable httperr.Response is returned.
+func (c *Config) NegotiateEncoder(writer http.ResponseWriter, request *http.Request) (Encoder, error) {
+	contenttype.AddVary(writer.Header(), "Accept")
+	encoders := c.encoders()
+	offers := make([]string, len(encoders))
+	for i, encoder := range encoders {
+		offers[i] = encoder.ContentType
+	}
+	best := contenttype.Negotiate(request.Header.Get("Accept"), offers...)
+	for _, encoder := range encoders {
+		if encoder.ContentType == best {
+			return encoder, nil
+		}
+	}
+	return Encoder{}, httperr.DontLog(httperr.Errorf(
+		http.StatusNotAcceptable,
+		"Not Acceptable, available content types: %s",
+		strings.Join(offers, ", "),
+	))
+}
+
+// WriteEncoded encodes the response with encoder and writes it
+// with the content type of the encoder.
+// The response is pretty-printed if PrettyPrint of the Config is true
+// and the encoder supports it.
+// If response is a Result, then its status code and headers
+// are written and its body is encoded.
+// If request is not nil, then the conditional headers of GET and HEAD
+// requests are evaluated and an ETag is added if AutoETag is true.
+// If encoding fails, the error is handled with HandleError as
+// 500 Internal Server Error rendered according to the request.
+func (c *Config) WriteEncoded(writer http.ResponseWriter, request *http.Request, encoder Encoder, response any) {
+	response, statusCode, writeBody := c.applyResult(writer, request, response)
+	if !writeBody {
+		return
+	}
+	b, err := c.Encode(encoder, response)
+	if err != nil {
+		c.HandleError(err, writer, request)
+		return
+	}
+	writer.Header().Set("Content-Type", encoder.ContentType)
+	c.writeBody(writer, request, statusCode, b)
+}
@@ -0,0 +1,149 @@
+package respond
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ungerik/go-httpx/contenttype"
+)
+
+func ExampleNegotiated() {
+	type Status struct {
+		OK bool `json:"ok" xml:"ok"`
+	}
+	handler := Negotiated(func(w http.ResponseWriter, r *http.Request) (any, error) {
+		return Status{OK: true}, nil
+	})
+
+	for _, accept := range []string{"", "application/xml", "text/*;q=0.5, application/json;q=0.1", "image/png"} {
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		request.Header.Set("Accept", accept)
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		fmt.Println(recorder.Code, recorder.Header().Get("Content-Type"), recorder.Header().Get("Vary"))
+	}
+
+	// Output:
+	// 200 application/json; charset=utf-8 Accept
+	// 200 application/xml Accept
+	// 200 text/plain; charset=utf-8 Accept
+	// 406 text/plain; charset=utf-8 Accept
+}
+
+func TestNegotiated(t *testing.T) {
+	type Status struct {
+		OK bool `json:"ok" xml:"ok"`
+	}
+	handler := Negotiated(func(w http.ResponseWriter, r *http.Request) (any, error) {
+		return Status{OK: true}, nil
+	})
+	tests := []struct {
+		accept     string
+		wantStatus int
+		wantType   string
+	}{
+		{"", http.StatusOK, contenttype.JSON},
+		{"*/*", http.StatusOK, contenttype.JSON},
+		{"application/yaml", http.StatusOK, contenttype.YAML},
+		{"application/msgpack", http.StatusOK, contenttype.MessagePack},
+		{"application/cbor", http.StatusOK, contenttype.CBOR},
+		{"application/json;q=0.5, application/cbor", http.StatusOK, contenttype.CBOR},
+		{"application/json;q=0, */*;q=0.1", http.StatusOK, contenttype.XML},
+		{"text/*", http.StatusOK, contenttype.PlainText},
+		{"image/png", http.StatusNotAcceptable, ""},
+		{"application/json;q=0", http.StatusNotAcceptable, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/", nil)
+			request.Header.Set("Accept", tt.accept)
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+			if tt.wantType != "" && recorder.Header().Get("Content-Type") != tt.wantType {
+				t.Errorf("Content-Type = %q, want %q", recorder.Header().Get("Content-Type"), tt.wantType)
+			}
+			if vary := recorder.Header().Values("Vary"); len(vary) != 1 || vary[0] != "Accept" {
+				t.Errorf("Vary = %q, want [Accept]", vary)
+			}
+		})
+	}
+}
+
+func TestNegotiatedXML(t *testing.T) {
+	type Status struct {
+		XMLName struct{} `xml:"status"`
+		OK      bool     `xml:"ok"`
+	}
+	for _, prettyPrint := range []bool{false, true} {
+		t.Run(fmt.Sprintf("PrettyPrint=%t", prettyPrint), func(t *testing.T) {
+			config := DefaultConfig()
+			config.PrettyPrint = prettyPrint
+			handler := config.Bind(Negotiated(func(w http.ResponseWriter, r *http.Request) (any, error) {
+				return Status{OK: true}, nil
+			}))
+			request := httptest.NewRequest(http.MethodGet, "/", nil)
+			request.Header.Set("Accept", "application/xml")
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+
+			want := httptest.NewRecorder()
+			config.WriteXML(want, nil, Status{OK: true})
+			if got := recorder.Body.String(); got != want.Body.String() {
+				t.Errorf("body = %q, want WriteXML output %q", got, want.Body.String())
+			}
+			if !strings.HasPrefix(recorder.Body.String(), xml.Header) {
+				t.Errorf("body = %q, want XML header", recorder.Body.String())
+			}
+		})
+	}
+}
+
+func TestRegisterEncoder(t *testing.T) {
+	defer func(encoders []Encoder) { Encoders = encoders }(append([]Encoder(nil), Encoders...))
+
+	encode := func(response any) ([]byte, error) { return []byte("compact"), nil }
+	encodeIndent := func(response any, indent string) ([]byte, error) { return []byte("indented"), nil }
+	RegisterEncoder(Encoder{ContentType: "application/toml", Encode: encode, EncodeIndent: encodeIndent})
+	RegisterEncoder(Encoder{ContentType: contenttype.YAML + "; charset=utf-8", Encode: encode, EncodeIndent: encodeIndent})
+
+	count := 0
+	for _, encoder := range Encoders {
+		if contenttype.MediaType(encoder.ContentType) == "application/yaml" {
+			count++
+		}
+	}
+	if count != 1 {
+		t.Errorf("%d encoders for application/yaml, want the replaced one", count)
+	}
+
+	config := DefaultConfig()
+	config.PrettyPrint = true
+	handler := config.Bind(Negotiated(func(w http.ResponseWriter, r *http.Request) (any, error) {
+		return "x", nil
+	}))
+	tests := []struct {
+		accept string
+		want   string
+	}{
+		{"application/toml", "indented"},
+		{"application/yaml", "indented"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/", nil)
+			request.Header.Set("Accept", tt.accept)
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+			if got := recorder.Body.String(); got != tt.want {
+				t.Errorf("body = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -4,13 +4,17 @@ import (
 	"net/http"
 
 	"github.com/ungerik/go-httpx/contenttype"
-	"github.com/ungerik/go-httpx/httperr"
 )
 
 // Plaintext is a handler type for functions that return plain text content.
 // The returned string is automatically written with Content-Type: text/plain.
 // Any error is handled by httperr.Handle.
 //
+// The handler function can set response headers and call WriteHeader
+// to use a different status code than 200 OK, the status code is
+// written together with the returned text. An empty response with
+// 204 No Content or 304 Not Modified is written without Content-Type.
+//
 // Example:
 //
 //	http.Handle("/status", respond.Plaintext(func(w http.ResponseWriter, r *http.Request) (string, error) {
@@ -21,20 +25,21 @@ type Plaintext func(http.ResponseWriter, *http.Request) (string, error)
 
 // ServeHTTP implements http.Handler for Plaintext.
 // It calls the handler function, handles any error, and writes the plain text response.
-// If CatchPanics is true, panics are recovered and handled as errors.
+// If CatchPanics of the request's Config is true, panics are recovered and handled as errors.
 func (handlerFunc Plaintext) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
-	if CatchPanics {
-		defer func() {
-			httperr.HandlePanic(recover(), writer, request)
-		}()
+	config := RequestConfig(request)
+	writer, commit := config.bufferResponse(writer)
+	defer commit()
+	if config.CatchPanics {
+		defer config.recoverPanic(writer, request)
 	}
 
-	response, err := handlerFunc(writer, request)
-	if httperr.Handle(err, writer, request) {
+	deferred := &deferredStatusWriter{ResponseWriter: writer}
+	response, err := handlerFunc(deferred, request)
+	if config.HandleError(err, writer, request) {
 		return
 	}
-
-	WritePlaintext(writer, response)
+	config.writeDeferred(deferred, request, contenttype.PlainText, []byte(response))
 }
 
 // StaticPlaintext is a handler type for serving static plain text content.
@@ -48,7 +53,8 @@ type StaticPlaintext string
 // ServeHTTP implements http.Handler for StaticPlaintext.
 // It writes the static plain text content on every request.
 func (s StaticPlaintext) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
-	WritePlaintext(writer, string(s))
+	writer.Header().Add("Content-Type", contenttype.PlainText)
+	RequestConfig(request).writeBody(writer, request, 0, []byte(s))
 }
 
 // WritePlaintext writes the plain text response with the appropriate content type.
@@ -0,0 +1,184 @@
+package respond
+
+import (
+	"net/http"
+	"time"
+)
+
+// Result wraps a response body returned by handler functions
+// like JSON, XML, JSONOf, or Negotiated to control
+// the status code and headers of the response.
+//
+// A Result with a nil Body writes only the status code and headers
+// without a body or Content-Type, using 204 No Content if Status is zero.
+//
+// The ETag and LastModified validators are written as headers
+// and used to answer conditional GET and HEAD requests
+// with 304 Not Modified or 412 Precondition Failed.
+//
+// Example:
+//
+//	http.Handle("/api/users", respond.JSON(func(w http.ResponseWriter, r *http.Request) (any, error) {
+//	    user, err := db.CreateUser(r)
+//	    if err != nil {
+//	        return nil, err
+//	    }
+//	    return respond.Created("/api/users/"+user.ID, user), nil
+//	}))
+type Result struct {
+	// Status is the status code of the response, 200 OK if zero
+	Status int
+	// Header is added to the response headers
+	Header http.Header
+	// Body is encoded as response body
+	Body any
+	// ETag is the optional entity tag of the body,
+	// it is quoted if it is not already quoted
+	ETag string
+	// LastModified is the optional modification time of the body
+	LastModified time.Time
+}
+
+// Status returns a Result with the passed status code and body.
+func Status(statusCode int, body any) *Result {
+	return &Result{Status: statusCode, Body: body}
+}
+
+// Created returns a 201 Created Result with
+// the Location header set to location and body.
+func Created(location string, body any) *Result {
+	return &Result{
+		Status: http.StatusCreated,
+		Header: http.Header{"Location": {location}},
+		Body:   body,
+	}
+}
+
+// Accepted returns a 202 Accepted Result for
+// asynchronous work with an optional body.
+func Accepted(body any) *Result {
+	return &Result{Status: http.StatusAccepted, Body: body}
+}
+
+// NoContent returns a 204 No Content Result
+// that writes no body and no Content-Type.
+func NoContent() *Result {
+	return &Result{Status: http.StatusNoContent}
+}
+
+// Attachment returns a Result with a Content-Disposition header
+// that makes browsers download body as file with filename.
+// The filename is encoded with ContentDisposition.
+func Attachment(filename string, body any) *Result {
+	return &Result{
+		Header: http.Header{"Content-Disposition": {ContentDisposition("attachment", filename)}},
+		Body:   body,
+	}
+}
+
+// applyResult adds the headers of response if it is a Result
+// and returns its body and status code.
+// If the Result has no body, then the preconditions of the request
+// are evaluated, the status code or 304 Not Modified is written,
+// and writeBody is false.
+func (c *Config) applyResult(writer http.ResponseWriter, request *http.Request, response any) (body any, statusCode int, writeBody bool) {
+	var result *Result
+	switch x := response.(type) {
+	case *Result:
+		result = x
+	case Result:
+		result = &x
+	}
+	if result == nil {
+		return response, 0, true
+	}
+	for key, values := range result.Header {
+		writer.Header()[http.CanonicalHeaderKey(key)] = values
+	}
+	if result.ETag != "" {
+		writer.Header().Set("ETag", quoteETag(result.ETag))
+	}
+	if !result.LastModified.IsZero() {
+		writer.Header().Set("Last-Modified", result.LastModified.UTC().Format(http.TimeFormat))
+	}
+	if result.Body == nil {
+		statusCode = result.Status
+		if statusCode == 0 {
+			statusCode = http.StatusNoContent
+		}
+		if isConditional(request, statusCode) && c.handlePreconditions(writer, request) {
+			return nil, statusCode, false
+		}
+		writer.WriteHeader(statusCode)
+		return nil, statusCode, false
+	}
+	return result.Body, result.Status, true
+}
+
+// deferredStatusWriter records the status code written by
+// handler functions like HTML and Plaintext and writes it
+// together with the first body bytes, so that the
+// Content-Type header can still be set after the handler returned.
+// Informational 1xx status codes are written immediately.
+type deferredStatusWriter struct {
+	http.ResponseWriter
+	statusCode  int
+	wroteHeader bool
+}
+
+func (w *deferredStatusWriter) WriteHeader(statusCode int) {
+	if statusCode < 200 {
+		w.ResponseWriter.WriteHeader(statusCode)
+		return
+	}
+	if !w.wroteHeader && w.statusCode == 0 {
+		w.statusCode = statusCode
+	}
+}
+
+func (w *deferredStatusWriter) Write(b []byte) (int, error) {
+	w.writeHeader()
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher
+func (w *deferredStatusWriter) Flush() {
+	w.writeHeader()
+	http.NewResponseController(w.ResponseWriter).Flush() //#nosec G104
+}
+
+// Unwrap returns the wrapped http.ResponseWriter
+// for http.ResponseController
+func (w *deferredStatusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// status returns the status code that has been
+// or will be written, defaulting to 200 OK
+func (w *deferredStatusWriter) status() int {
+	if w.statusCode == 0 {
+		return http.StatusOK
+	}
+	return w.statusCode
+}
+
+func (w *deferredStatusWriter) writeHeader() {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	if w.statusCode != 0 {
+		w.ResponseWriter.WriteHeader(w.statusCode)
+	}
+}
+
+// writeBodyless writes the recorded status code without
+// a Content-Type if it is a status that must not have a body
+// and the body is empty.
+func (w *deferredStatusWriter) writeBodyless(bodyLen int) bool {
+	if bodyLen > 0 || (w.statusCode != http.StatusNoContent && w.statusCode != http.StatusNotModified) {
+		return false
+	}
+	w.writeHeader()
+	return true
+}
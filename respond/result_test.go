@@ -0,0 +1,152 @@
+package respond
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func ExampleCreated() {
+	handlers := []http.Handler{
+		JSON(func(w http.ResponseWriter, r *http.Request) (any, error) {
+			return Created("/users/1", map[string]int{"id": 1}), nil
+		}),
+		XML(func(w http.ResponseWriter, r *http.Request) (any, error) {
+			return NoContent(), nil
+		}),
+		Plaintext(func(w http.ResponseWriter, r *http.Request) (string, error) {
+			w.WriteHeader(http.StatusAccepted)
+			return "queued", nil
+		}),
+	}
+	for _, handler := range handlers {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/users", nil))
+		fmt.Printf("%d %q %q %q\n", recorder.Code, recorder.Header().Get("Content-Type"), recorder.Header().Get("Location"), recorder.Body.String())
+	}
+
+	// Output:
+	// 201 "application/json; charset=utf-8" "/users/1" "{\n  \"id\": 1\n}"
+	// 204 "" "" ""
+	// 202 "text/plain; charset=utf-8" "" "queued"
+}
+
+func TestResult(t *testing.T) {
+	modified := time.Date(2024, 1, 2, 3, 4, 5, 0, time.FixedZone("CET", 3600))
+	tests := []struct {
+		name       string
+		response   any
+		header     http.Header
+		wantStatus int
+		wantHeader http.Header
+		wantBody   string
+	}{
+		{"nil body defaults to 204", &Result{}, nil, 204, http.Header{}, ""},
+		{"nil body keeps status", Status(http.StatusAccepted, nil), nil, 202, http.Header{}, ""},
+		{"value Result", Result{Status: http.StatusTeapot, Body: "tea"}, nil, 418, http.Header{"Content-Type": {"application/json; charset=utf-8"}}, `"tea"`},
+		{"zero status with body", &Result{Body: 1}, nil, 200, http.Header{"Content-Type": {"application/json; charset=utf-8"}}, "1"},
+		{"Accepted without body", Accepted(nil), nil, 202, http.Header{}, ""},
+		{"canonical header keys", &Result{Header: http.Header{"x-request-id": {"1"}}}, nil, 204, http.Header{"X-Request-Id": {"1"}}, ""},
+		{"quoted ETag", &Result{ETag: "v1"}, nil, 204, http.Header{"Etag": {`"v1"`}}, ""},
+		{"already quoted ETag", &Result{ETag: `W/"v1"`}, nil, 204, http.Header{"Etag": {`W/"v1"`}}, ""},
+		{"Last-Modified in UTC", &Result{LastModified: modified}, nil, 204, http.Header{"Last-Modified": {"Tue, 02 Jan 2024 02:04:05 GMT"}}, ""},
+		{"Attachment", Attachment("a b.txt", nil), nil, 204, http.Header{"Content-Disposition": {ContentDisposition("attachment", "a b.txt")}}, ""},
+		{"Created", Created("/x/1", nil), nil, 201, http.Header{"Location": {"/x/1"}}, ""},
+		{"bodyless If-None-Match", &Result{Status: http.StatusOK, ETag: "abc"}, http.Header{"If-None-Match": {`"abc"`}}, 304, http.Header{"Etag": {`"abc"`}}, ""},
+		{"bodyless If-None-Match mismatch", &Result{Status: http.StatusOK, ETag: "abc"}, http.Header{"If-None-Match": {`"xyz"`}}, 200, http.Header{"Etag": {`"abc"`}}, ""},
+		{"bodyless If-Modified-Since", &Result{Status: http.StatusOK, LastModified: modified}, http.Header{"If-Modified-Since": {"Tue, 02 Jan 2024 02:04:05 GMT"}}, 304, http.Header{"Last-Modified": {"Tue, 02 Jan 2024 02:04:05 GMT"}}, ""},
+		{"bodyless If-Match failed", &Result{Status: http.StatusOK, ETag: "abc"}, http.Header{"If-Match": {`"xyz"`}}, 412, nil, ""},
+		{"bodyless 204 is not conditional", &Result{ETag: "abc"}, http.Header{"If-None-Match": {`"abc"`}}, 204, http.Header{"Etag": {`"abc"`}}, ""},
+		{"If-None-Match with body", &Result{Body: 1, ETag: "abc"}, http.Header{"If-None-Match": {`"abc"`}}, 304, http.Header{"Etag": {`"abc"`}}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := JSON(func(w http.ResponseWriter, r *http.Request) (any, error) {
+				return tt.response, nil
+			})
+			request := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != nil {
+				request.Header = tt.header
+			}
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+			if tt.wantHeader == nil {
+				return
+			}
+			header := recorder.Header()
+			header.Del("X-Content-Type-Options")
+			if !reflect.DeepEqual(header, tt.wantHeader) {
+				t.Errorf("header = %v, want %v", header, tt.wantHeader)
+			}
+			if got := recorder.Body.String(); got != tt.wantBody {
+				t.Errorf("body = %q, want %q", got, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestDeferredStatusWriter(t *testing.T) {
+	tests := []struct {
+		name       string
+		handler    Plaintext
+		wantStatus int
+		wantBody   string
+		wantType   string
+	}{
+		{
+			name:       "default status",
+			handler:    func(http.ResponseWriter, *http.Request) (string, error) { return "ok", nil },
+			wantStatus: 200,
+			wantBody:   "ok",
+			wantType:   "text/plain; charset=utf-8",
+		},
+		{
+			name: "first status wins",
+			handler: func(w http.ResponseWriter, r *http.Request) (string, error) {
+				w.WriteHeader(http.StatusCreated)
+				w.WriteHeader(http.StatusAccepted)
+				return "x", nil
+			},
+			wantStatus: 201,
+			wantBody:   "x",
+			wantType:   "text/plain; charset=utf-8",
+		},
+		{
+			name: "no content without body",
+			handler: func(w http.ResponseWriter, r *http.Request) (string, error) {
+				w.WriteHeader(http.StatusNoContent)
+				return "", nil
+			},
+			wantStatus: 204,
+		},
+		{
+			name: "not modified without body",
+			handler: func(w http.ResponseWriter, r *http.Request) (string, error) {
+				w.WriteHeader(http.StatusNotModified)
+				return "", nil
+			},
+			wantStatus: 304,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			tt.handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+			if got := recorder.Body.String(); got != tt.wantBody {
+				t.Errorf("body = %q, want %q", got, tt.wantBody)
+			}
+			if got := recorder.Header().Get("Content-Type"); got != tt.wantType {
+				t.Errorf("Content-Type = %q, want %q", got, tt.wantType)
+			}
+		})
+	}
+}
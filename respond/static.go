@@ -0,0 +1,237 @@
+package respond
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/ungerik/go-httpx/compress"
+	"github.com/ungerik/go-httpx/contenttype"
+	"github.com/ungerik/go-httpx/httperr"
+)
+
+// StaticFiles is a http.Handler serving the files of an fs.FS
+// like an embed.FS with a frontend build.
+// Create it with NewStaticFiles.
+//
+// Strong ETags are computed for all files when the StaticFiles are created,
+// so the files of the fs.FS must not change afterwards.
+// Files with a content hash in their name, as determined by IsImmutable,
+// are served with a Cache-Control header for one year,
+// all other files must be revalidated by clients with their ETag.
+//
+// If the request accepts gzip and a file has a sibling with
+// the additional extension ".gz", then the pre-compressed sibling
+// is served with Content-Encoding gzip.
+//
+// Requests for directories serve their index.html file.
+// Like by http.FileServer, requests for directories without
+// a trailing slash are redirected to the path with a trailing slash
+// using a relative Location, so that relative links of index.html work
+// and the StaticFiles can be used with http.StripPrefix.
+// Paths that don't exist, and paths with a segment starting with a dot,
+// result in 404 Not Found and directories without index.html
+// in 403 Forbidden errors, which are handled with the ErrorHandler
+// of the request's Config, so httperr.Handle by default.
+//
+// Example:
+//
+//	//go:embed dist
+//	var dist embed.FS
+//
+//	frontend, err := fs.Sub(dist, "dist")
+//	...
+//	static, err := respond.NewStaticFiles(frontend)
+//	...
+//	static.SPAFallback = "index.html"
+//	http.Handle("/", static)
+type StaticFiles struct {
+	fsys  fs.FS
+	etags map[string]string
+
+	// SPAFallback is the path of the file served instead of
+	// a 404 Not Found error for unknown paths without a file extension,
+	// like "index.html" for single page applications with client side routing.
+	// No fallback is used if empty.
+	SPAFallback string
+
+	// IsImmutable returns true for files that are served with a
+	// long-lived Cache-Control header because their name changes
+	// with their content. Initialized with IsHashedFilename.
+	IsImmutable func(name string) bool
+}
+
+// NewStaticFiles returns StaticFiles serving fsys
+// after computing the ETags of all its files.
+func NewStaticFiles(fsys fs.FS) (*StaticFiles, error) {
+	etags := make(map[string]string)
+	err := fs.WalkDir(fsys, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || !entry.Type().IsRegular() {
+			return err
+		}
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		etags[name] = ETag(data)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &StaticFiles{
+		fsys:        fsys,
+		etags:       etags,
+		IsImmutable: IsHashedFilename,
+	}, nil
+}
+
+// ServeHTTP implements http.Handler for StaticFiles.
+func (s *StaticFiles) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	config := RequestConfig(request)
+	if request.Method != http.MethodGet && request.Method != http.MethodHead {
+		config.HandleError(httperr.DontLog(httperr.MethodNotAllowedFor(http.MethodGet, http.MethodHead)), writer, request)
+		return
+	}
+
+	name := strings.TrimPrefix(path.Clean("/"+request.URL.Path), "/")
+	if name == "" {
+		name = "."
+	}
+	name, dir, err := s.resolve(name)
+	if dir && request.URL.Path != "" && !strings.HasSuffix(request.URL.Path, "/") {
+		localRedirect(writer, request, url.PathEscape(path.Base(request.URL.Path))+"/")
+		return
+	}
+	if err != nil && s.SPAFallback != "" && httperr.StatusCode(err) == http.StatusNotFound && path.Ext(name) == "" {
+		name, err = s.SPAFallback, nil
+	}
+	if config.HandleError(err, writer, request) {
+		return
+	}
+
+	s.serveFile(config, writer, request, name)
+}
+
+// resolve returns the name of the file to serve for name
+// or a 404 Not Found or 403 Forbidden error,
+// and if name is a directory
+func (s *StaticFiles) resolve(name string) (file string, dir bool, err error) {
+	if !fs.ValidPath(name) {
+		return name, false, httperr.DontLog(httperr.NotFound)
+	}
+	for _, segment := range strings.Split(name, "/") {
+		if strings.HasPrefix(segment, ".") && segment != "." {
+			return name, false, httperr.DontLog(httperr.NotFound)
+		}
+	}
+	if _, ok := s.etags[name]; ok {
+		return name, false, nil
+	}
+	info, err := fs.Stat(s.fsys, name)
+	if err != nil || !info.IsDir() {
+		return name, false, httperr.DontLog(httperr.NotFound)
+	}
+	index := path.Join(name, "index.html")
+	if _, ok := s.etags[index]; ok {
+		return index, true, nil
+	}
+	return name, true, httperr.DontLog(httperr.Forbidden)
+}
+
+// localRedirect redirects to the target path relative
+// to the request URL like http.FileServer
+func localRedirect(writer http.ResponseWriter, request *http.Request, target string) {
+	if request.URL.RawQuery != "" {
+		target += "?" + request.URL.RawQuery
+	}
+	writer.Header().Set("Location", target)
+	writer.WriteHeader(http.StatusMovedPermanently)
+}
+
+func (s *StaticFiles) serveFile(config *Config, writer http.ResponseWriter, request *http.Request, name string) {
+	header := writer.Header()
+	contentType := mime.TypeByExtension(path.Ext(name))
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	if s.IsImmutable != nil && s.IsImmutable(name) {
+		header.Set("Cache-Control", "public, max-age=31536000, immutable")
+	} else {
+		header.Set("Cache-Control", "no-cache")
+	}
+
+	serveName := name
+	if _, ok := s.etags[name+".gz"]; ok {
+		contenttype.AddVary(header, "Accept-Encoding")
+		if compress.AcceptsEncoding(request.Header.Get("Accept-Encoding"), "gzip") {
+			serveName = name + ".gz"
+			header.Set("Content-Encoding", "gzip")
+		}
+	}
+
+	file, err := s.fsys.Open(serveName)
+	if err != nil {
+		header.Del("Content-Encoding")
+		config.HandleError(err, writer, request)
+		return
+	}
+	defer file.Close() //#nosec G104
+	info, err := file.Stat()
+	if err != nil {
+		header.Del("Content-Encoding")
+		config.HandleError(err, writer, request)
+		return
+	}
+	content, ok := file.(io.ReadSeeker)
+	if !ok {
+		data, err := io.ReadAll(file)
+		if err != nil {
+			header.Del("Content-Encoding")
+			config.HandleError(err, writer, request)
+			return
+		}
+		content = bytes.NewReader(data)
+	}
+
+	config.ServeFileContent(writer, request, &FileContent{
+		Content:     content,
+		ModTime:     info.ModTime(),
+		ContentType: contentType,
+		ETag:        s.etags[serveName],
+		Inline:      true,
+	})
+}
+
+// IsHashedFilename returns true if the last dot or dash separated
+// part of the file name before its extension looks like a content hash
+// added by frontend build tools, like in "app.3f2a9c1b.js" or "index-BQ2iXKmZ.css".
+// It must have at least 8 hex or base64url characters including a digit,
+// so that words like in "logo-Trademark.svg" are not mistaken for hashes.
+// Set IsImmutable of StaticFiles to a different function
+// for the naming scheme of other build tools.
+func IsHashedFilename(name string) bool {
+	base := path.Base(name)
+	base = strings.TrimSuffix(base, ".gz")
+	base = strings.TrimSuffix(base, path.Ext(base))
+	hash := base[strings.LastIndexAny(base, ".-")+1:]
+	if len(hash) < 8 || len(base) == len(hash) {
+		return false
+	}
+	digit := false
+	for _, c := range hash {
+		switch {
+		case c >= '0' && c <= '9':
+			digit = true
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c == '_':
+		default:
+			return false
+		}
+	}
+	return digit
+}
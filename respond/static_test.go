@@ -0,0 +1,120 @@
+package respond
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+)
+
+func ExampleStaticFiles() {
+	static, err := NewStaticFiles(fstest.MapFS{
+		"index.html":                {Data: []byte("<h1>App</h1>")},
+		"assets/app.3f2a9c1b.js":    {Data: []byte("console.log('app')")},
+		"assets/app.3f2a9c1b.js.gz": {Data: []byte("\x1f\x8b...")},
+	})
+	if err != nil {
+		panic(err)
+	}
+	static.SPAFallback = "index.html"
+
+	for _, path := range []string{"/", "/assets/app.3f2a9c1b.js", "/users/42", "/assets/missing.js", "/assets", "/assets/"} {
+		request := httptest.NewRequest(http.MethodGet, path, nil)
+		request.Header.Set("Accept-Encoding", "gzip")
+		recorder := httptest.NewRecorder()
+		static.ServeHTTP(recorder, request)
+		fmt.Printf("%s %d %q %q\n", path, recorder.Code, recorder.Header().Get("Content-Encoding"), recorder.Header().Get("Cache-Control"))
+	}
+
+	// Output:
+	// / 200 "" "no-cache"
+	// /assets/app.3f2a9c1b.js 200 "gzip" "public, max-age=31536000, immutable"
+	// /users/42 200 "" "no-cache"
+	// /assets/missing.js 404 "" ""
+	// /assets 301 "" ""
+	// /assets/ 403 "" ""
+}
+
+func TestIsHashedFilename(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"app.3f2a9c1b.js", true},
+		{"assets/index-BQ2iXKmZ.css", true},
+		{"chunk-a1b2c3d4e5f6.js.gz", true},
+		{"font.Ab_9xYz0.woff2", true},
+		{"logo-Trademark.svg", false},
+		{"app.MainPage.js", false},
+		{"index-DiwrgTda.js", false},
+		{"app.3f2a9c1.js", false},
+		{"3f2a9c1b.js", false},
+		{"app.3f2a9c1b", false},
+		{"app.3f2a~9c1b.js", false},
+		{"index.html", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsHashedFilename(tt.name); got != tt.want {
+				t.Errorf("IsHashedFilename(%q) = %t, want %t", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStaticFiles(t *testing.T) {
+	static, err := NewStaticFiles(fstest.MapFS{
+		"index.html":         {Data: []byte("root")},
+		"docs/index.html":    {Data: []byte("docs")},
+		"empty/.keep":        {Data: []byte("")},
+		".env":               {Data: []byte("SECRET=1")},
+		"logo-Trademark.svg": {Data: []byte("<svg/>")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		method       string
+		url          string
+		wantStatus   int
+		wantLocation string
+		wantBody     string
+	}{
+		{http.MethodGet, "/", http.StatusOK, "", "root"},
+		{http.MethodGet, "/index.html", http.StatusOK, "", "root"},
+		{http.MethodGet, "/docs", http.StatusMovedPermanently, "docs/", ""},
+		{http.MethodGet, "/docs?page=2", http.StatusMovedPermanently, "docs/?page=2", ""},
+		{http.MethodGet, "/docs/", http.StatusOK, "", "docs"},
+		{http.MethodGet, "/empty", http.StatusMovedPermanently, "empty/", ""},
+		{http.MethodGet, "/empty/", http.StatusForbidden, "", ""},
+		{http.MethodGet, "/.env", http.StatusNotFound, "", ""},
+		{http.MethodGet, "/empty/.keep", http.StatusNotFound, "", ""},
+		{http.MethodGet, "/../index.html", http.StatusOK, "", "root"},
+		{http.MethodGet, "/missing", http.StatusNotFound, "", ""},
+		{http.MethodHead, "/", http.StatusOK, "", ""},
+		{http.MethodPost, "/", http.StatusMethodNotAllowed, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.url, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			static.ServeHTTP(recorder, httptest.NewRequest(tt.method, tt.url, nil))
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+			if location := recorder.Header().Get("Location"); location != tt.wantLocation {
+				t.Errorf("Location = %q, want %q", location, tt.wantLocation)
+			}
+			if tt.wantBody != "" && recorder.Body.String() != tt.wantBody {
+				t.Errorf("body = %q, want %q", recorder.Body.String(), tt.wantBody)
+			}
+		})
+	}
+
+	recorder := httptest.NewRecorder()
+	static.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/logo-Trademark.svg", nil))
+	if cacheControl := recorder.Header().Get("Cache-Control"); cacheControl != "no-cache" {
+		t.Errorf("Cache-Control of logo-Trademark.svg = %q, want %q", cacheControl, "no-cache")
+	}
+}
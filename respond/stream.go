@@ -0,0 +1,202 @@
+package respond
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/ungerik/go-httpx/contenttype"
+	"github.com/ungerik/go-httpx/httperr"
+)
+
+// JSONStream is a handler type for functions that stream JSON values
+// by calling send for every value instead of returning all values at once,
+// so that large responses don't have to be held in memory.
+//
+// The values are written as newline-delimited JSON (application/x-ndjson)
+// or, if the Accept header of the request prefers application/json,
+// as JSON array with one element per line.
+// The response is flushed after every value.
+// If neither format is acceptable, then a 406 Not Acceptable error
+// is handled without calling the handler function.
+//
+// send returns the error of the request context after it was canceled,
+// or the error of marshalling or writing the value.
+// The handler function should return such errors.
+//
+// Errors returned before the first value was sent are handled
+// like by the JSON handler with a complete error response.
+// After the first value was sent, the status code has already been written,
+// so the error is logged with httperr.Logger if ShouldLog returns true
+// and the response is aborted with http.ErrAbortHandler
+// without terminating the NDJSON stream or JSON array,
+// so that clients can detect the incomplete response.
+// Errors after the request context was canceled are ignored.
+//
+// Example:
+//
+//	http.Handle("/api/export", respond.JSONStream(func(w http.ResponseWriter, r *http.Request, send func(any) error) error {
+//	    rows, err := db.QueryContext(r.Context(), "SELECT * FROM users")
+//	    if err != nil {
+//	        return err
+//	    }
+//	    defer rows.Close()
+//	    for rows.Next() {
+//	        var user User
+//	        if err := rows.Scan(&user.ID, &user.Name); err != nil {
+//	            return err
+//	        }
+//	        if err := send(user); err != nil {
+//	            return err
+//	        }
+//	    }
+//	    return rows.Err()
+//	}))
+type JSONStream func(writer http.ResponseWriter, request *http.Request, send func(value any) error) error
+
+// ServeHTTP implements http.Handler for JSONStream.
+// It negotiates the stream format, calls the handler function,
+// and handles any error depending on whether the stream has already started.
+// If CatchPanics of the request's Config is true, panics are recovered and handled as errors.
+func (handlerFunc JSONStream) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	config := RequestConfig(request)
+	if config.CatchPanics {
+		defer config.recoverPanic(writer, request)
+	}
+
+	contenttype.AddVary(writer.Header(), "Accept")
+	contentType := contenttype.Negotiate(request.Header.Get("Accept"), contenttype.NDJSON, contenttype.JSON)
+	if contentType == "" {
+		config.HandleError(httperr.DontLog(httperr.Errorf(
+			http.StatusNotAcceptable,
+			"Not Acceptable, available content types: %s, %s",
+			contenttype.NDJSON,
+			contenttype.JSON,
+		)), writer, request)
+		return
+	}
+
+	stream := &jsonStreamWriter{
+		writer:      writer,
+		ctx:         request.Context(),
+		contentType: contentType,
+		array:       contentType == contenttype.JSON,
+	}
+	err := handlerFunc(writer, request, stream.send)
+	switch {
+	case err == nil:
+		stream.close()
+	case !stream.started:
+		config.HandleError(err, writer, request)
+	case request.Context().Err() == nil:
+		abortResponse(err, http.StatusOK, request)
+	}
+}
+
+// JSONStreamChannel returns a JSONStream handler that streams all values
+// received from the channel returned by handlerFunc until the channel
+// is closed or the request context is canceled.
+// A received value that is a non-nil error stops the stream with that error.
+//
+// The goroutine sending to the channel should stop
+// when the request context is canceled to not block forever.
+//
+// Example:
+//
+//	http.Handle("/api/events", respond.JSONStreamChannel(func(w http.ResponseWriter, r *http.Request) (<-chan Event, error) {
+//	    return events.Subscribe(r.Context())
+//	}))
+func JSONStreamChannel[T any](handlerFunc func(http.ResponseWriter, *http.Request) (<-chan T, error)) JSONStream {
+	return func(writer http.ResponseWriter, request *http.Request, send func(value any) error) error {
+		values, err := handlerFunc(writer, request)
+		if err != nil {
+			return err
+		}
+		for {
+			select {
+			case <-request.Context().Done():
+				return request.Context().Err()
+			case value, ok := <-values:
+				if !ok {
+					return nil
+				}
+				if err, isErr := any(value).(error); isErr && err != nil {
+					return err
+				}
+				if err := send(value); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// jsonStreamWriter writes the values of a JSONStream
+// and defers writing the status code until the first value
+type jsonStreamWriter struct {
+	writer      http.ResponseWriter
+	ctx         context.Context
+	contentType string
+	array       bool
+	started     bool
+}
+
+func (s *jsonStreamWriter) send(value any) error {
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("can't marshal stream value: %w", err)
+	}
+	var prefix string
+	switch {
+	case !s.started && s.array:
+		prefix = "[\n"
+	case s.array:
+		prefix = ",\n"
+	}
+	if !s.started {
+		s.start()
+	}
+	line := make([]byte, 0, len(prefix)+len(b)+1)
+	line = append(line, prefix...)
+	line = append(line, b...)
+	if !s.array {
+		line = append(line, '\n')
+	}
+	if _, err := s.writer.Write(line); err != nil {
+		return err
+	}
+	http.NewResponseController(s.writer).Flush() //#nosec G104
+	return nil
+}
+
+func (s *jsonStreamWriter) start() {
+	s.started = true
+	s.writer.Header().Set("Content-Type", s.contentType)
+	s.writer.WriteHeader(http.StatusOK)
+}
+
+func (s *jsonStreamWriter) close() {
+	switch {
+	case !s.started && s.array:
+		s.start()
+		s.writer.Write([]byte("[]\n")) //#nosec G104
+	case !s.started:
+		s.start()
+	case s.array:
+		s.writer.Write([]byte("\n]\n")) //#nosec G104
+	}
+}
+
+// abortResponse logs err that occurred after the response
+// was committed with statusCode and aborts the response,
+// so that clients can detect that it is incomplete.
+func abortResponse(err error, statusCode int, request *http.Request) {
+	if httperr.Logger != nil && httperr.ShouldLog(err) {
+		httperr.Logger.LogError(request, statusCode, err)
+	}
+	panic(http.ErrAbortHandler)
+}
@@ -0,0 +1,192 @@
+package respond
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ungerik/go-httpx/httperr"
+)
+
+func ExampleJSONStream() {
+	handler := JSONStream(func(w http.ResponseWriter, r *http.Request, send func(any) error) error {
+		for i := 1; i <= 3; i++ {
+			if err := send(map[string]int{"id": i}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	for _, accept := range []string{"", "application/json"} {
+		request := httptest.NewRequest(http.MethodGet, "/export", nil)
+		request.Header.Set("Accept", accept)
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		fmt.Println(recorder.Header().Get("Content-Type"))
+		fmt.Print(recorder.Body.String())
+	}
+
+	// Output:
+	// application/x-ndjson
+	// {"id":1}
+	// {"id":2}
+	// {"id":3}
+	// application/json; charset=utf-8
+	// [
+	// {"id":1},
+	// {"id":2},
+	// {"id":3}
+	// ]
+}
+
+func TestJSONStream(t *testing.T) {
+	failed := errors.New("failed")
+	sendN := func(n int, err error) JSONStream {
+		return func(w http.ResponseWriter, r *http.Request, send func(any) error) error {
+			for i := 1; i <= n; i++ {
+				if err := send(i); err != nil {
+					return err
+				}
+			}
+			return err
+		}
+	}
+	tests := []struct {
+		name       string
+		handler    JSONStream
+		accept     string
+		wantStatus int
+		wantType   string
+		wantBody   string
+		wantAbort  bool
+	}{
+		{"empty NDJSON", sendN(0, nil), "", 200, "application/x-ndjson", "", false},
+		{"empty array", sendN(0, nil), "application/json", 200, "application/json; charset=utf-8", "[]\n", false},
+		{"single array element", sendN(1, nil), "application/json", 200, "application/json; charset=utf-8", "[\n1\n]\n", false},
+		{"prefers NDJSON", sendN(1, nil), "application/json;q=0.5, application/x-ndjson", 200, "application/x-ndjson", "1\n", false},
+		{"not acceptable", sendN(1, nil), "text/html", 406, "", "", false},
+		{"error before first value", sendN(0, httperr.NotFound), "", 404, "", "", false},
+		{"error after first value", sendN(2, failed), "", 200, "application/x-ndjson", "1\n2\n", true},
+		{"unterminated array after error", sendN(1, failed), "application/json", 200, "application/json; charset=utf-8", "[\n1", true},
+		{
+			"marshal error",
+			func(w http.ResponseWriter, r *http.Request, send func(any) error) error {
+				return send(func() {})
+			},
+			"", 500, "", "", false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/", nil)
+			request.Header.Set("Accept", tt.accept)
+			recorder := httptest.NewRecorder()
+			aborted := false
+			func() {
+				defer func() {
+					if r := recover(); r != nil {
+						aborted = r == http.ErrAbortHandler
+						if !aborted {
+							panic(r)
+						}
+					}
+				}()
+				tt.handler.ServeHTTP(recorder, request)
+			}()
+			if aborted != tt.wantAbort {
+				t.Errorf("aborted = %t, want %t", aborted, tt.wantAbort)
+			}
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+			if tt.wantStatus != 200 {
+				return
+			}
+			if got := recorder.Header().Get("Content-Type"); got != tt.wantType {
+				t.Errorf("Content-Type = %q, want %q", got, tt.wantType)
+			}
+			if got := recorder.Body.String(); got != tt.wantBody {
+				t.Errorf("body = %q, want %q", got, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestJSONStreamCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var sendErr error
+	handler := JSONStream(func(w http.ResponseWriter, r *http.Request, send func(any) error) error {
+		send(1) //#nosec G104
+		cancel()
+		sendErr = send(2)
+		return sendErr
+	})
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx))
+	if !errors.Is(sendErr, context.Canceled) {
+		t.Errorf("send error = %v, want context.Canceled", sendErr)
+	}
+	if got := recorder.Body.String(); got != "1\n" {
+		t.Errorf("body = %q", got)
+	}
+}
+
+func TestJSONStreamChannel(t *testing.T) {
+	failed := errors.New("failed")
+	tests := []struct {
+		name       string
+		values     []any
+		err        error
+		wantStatus int
+		wantBody   string
+		wantAbort  bool
+	}{
+		{"closed channel", nil, nil, 200, "", false},
+		{"values", []any{"a", 1}, nil, 200, "\"a\"\n1\n", false},
+		{"nil error value is sent", []any{error(nil)}, nil, 200, "null\n", false},
+		{"error value before first value", []any{httperr.Forbidden}, nil, 403, "", false},
+		{"error value after first value", []any{1, failed, 2}, nil, 200, "1\n", true},
+		{"handler error", nil, httperr.NotFound, 404, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := JSONStreamChannel(func(w http.ResponseWriter, r *http.Request) (<-chan any, error) {
+				if tt.err != nil {
+					return nil, tt.err
+				}
+				values := make(chan any, len(tt.values))
+				for _, value := range tt.values {
+					values <- value
+				}
+				close(values)
+				return values, nil
+			})
+			recorder := httptest.NewRecorder()
+			aborted := false
+			func() {
+				defer func() {
+					if r := recover(); r != nil {
+						aborted = r == http.ErrAbortHandler
+						if !aborted {
+							panic(r)
+						}
+					}
+				}()
+				handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+			}()
+			if aborted != tt.wantAbort {
+				t.Errorf("aborted = %t, want %t", aborted, tt.wantAbort)
+			}
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == 200 && recorder.Body.String() != tt.wantBody {
+				t.Errorf("body = %q, want %q", recorder.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,264 @@
+package respond
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"net/http"
+	"path"
+	"sync"
+
+	"github.com/ungerik/go-httpx/contenttype"
+	"github.com/ungerik/go-httpx/httperr"
+)
+
+// Templates is a set of html/template page templates loaded from an fs.FS.
+// Every page is parsed together with all layout and partial templates
+// matching the Layouts patterns, so pages can define blocks
+// like "content" that are used by a shared layout.
+//
+// Call Parse after setting the fields and before rendering,
+// the fields must not be modified afterwards.
+//
+// Example:
+//
+//	templates := &respond.Templates{
+//	    FS:      os.DirFS("templates"),
+//	    Layouts: []string{"layouts/*.html", "partials/*.html"},
+//	    Pages:   []string{"pages/*.html", "errors/*.html"},
+//	    Layout:  "base",
+//	    Reload:  devMode,
+//	}
+//	err := templates.Parse()
+//	...
+//	respond.DefaultTemplates = templates
+//	httperr.SetErrorRenderer(contenttype.HTML, templates.ErrorRenderer("errors"))
+type Templates struct {
+	// FS contains the template files
+	FS fs.FS
+
+	// Layouts are fs.Glob patterns of layout and partial
+	// templates that are parsed into every page
+	Layouts []string
+
+	// Pages are fs.Glob patterns of the page templates.
+	// Pages are rendered by their path in FS, like "pages/index.html".
+	Pages []string
+
+	// Layout is the optional name of a template defined by a layout file
+	// that is executed for every page instead of the page file itself.
+	Layout string
+
+	// Funcs are added to the templates before parsing
+	Funcs template.FuncMap
+
+	// Reload controls whether the templates are parsed again
+	// on every render, so that changes are visible without restarting
+	// the server during development.
+	Reload bool
+
+	mutex sync.RWMutex
+	pages map[string]*template.Template
+}
+
+// Parse parses the templates from FS.
+// It returns an error if a template can't be parsed
+// or if the Pages patterns don't match any file.
+func (t *Templates) Parse() error {
+	pages, err := t.parse()
+	if err != nil {
+		return err
+	}
+	t.mutex.Lock()
+	t.pages = pages
+	t.mutex.Unlock()
+	return nil
+}
+
+func (t *Templates) parse() (map[string]*template.Template, error) {
+	base := template.New("").Funcs(t.Funcs)
+	for _, pattern := range t.Layouts {
+		matches, err := fs.Glob(t.FS, pattern)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range matches {
+			if _, err = base.ParseFS(t.FS, name); err != nil {
+				return nil, err
+			}
+		}
+	}
+	pages := make(map[string]*template.Template)
+	for _, pattern := range t.Pages {
+		matches, err := fs.Glob(t.FS, pattern)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range matches {
+			page, err := base.Clone()
+			if err != nil {
+				return nil, err
+			}
+			if _, err = page.ParseFS(t.FS, name); err != nil {
+				return nil, err
+			}
+			pages[name] = page
+		}
+	}
+	if len(pages) == 0 {
+		return nil, fmt.Errorf("no page templates found for patterns %q", t.Pages)
+	}
+	return pages, nil
+}
+
+// Has returns true if there is a page template with name.
+func (t *Templates) Has(name string) bool {
+	pages, err := t.load()
+	return err == nil && pages[name] != nil
+}
+
+// Render executes the page template with name and data
+// and writes the result to writer.
+// The Layout template is executed if it is set.
+func (t *Templates) Render(writer io.Writer, name string, data any) error {
+	pages, err := t.load()
+	if err != nil {
+		return err
+	}
+	page := pages[name]
+	if page == nil {
+		return fmt.Errorf("page template %q not found", name)
+	}
+	return t.execute(writer, page, name, data)
+}
+
+func (t *Templates) execute(writer io.Writer, page *template.Template, name string, data any) error {
+	if t.Layout != "" {
+		return page.ExecuteTemplate(writer, t.Layout, data)
+	}
+	return page.ExecuteTemplate(writer, path.Base(name), data)
+}
+
+func (t *Templates) load() (map[string]*template.Template, error) {
+	if t.Reload {
+		return t.parse()
+	}
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	if t.pages == nil {
+		return nil, errors.New("templates not parsed")
+	}
+	return t.pages, nil
+}
+
+// ErrorPage is the data used to render error page templates
+// with the ErrorRenderer of Templates.
+type ErrorPage struct {
+	StatusCode int
+	Status     string
+	Message    string
+	Request    *http.Request
+}
+
+// ErrorRenderer returns a httperr.ErrorRenderer that renders
+// HTML error pages with ErrorPage data using the first existing
+// page template of dir named like the status code, like "404.html",
+// the class of the status code, like "4xx.html", or "error.html".
+// If there is no such template or rendering fails,
+// then httperr.RenderErrorHTML is used.
+//
+// Example:
+//
+//	httperr.SetErrorRenderer(contenttype.HTML, templates.ErrorRenderer("errors"))
+func (t *Templates) ErrorRenderer(dir string) httperr.ErrorRenderer {
+	return httperr.ErrorRendererFunc(func(writer http.ResponseWriter, request *http.Request, statusCode int, message string) {
+		pages, err := t.load()
+		if err != nil {
+			httperr.RenderErrorHTML(writer, request, statusCode, message)
+			return
+		}
+		names := []string{
+			fmt.Sprintf("%d.html", statusCode),
+			fmt.Sprintf("%dxx.html", statusCode/100),
+			"error.html",
+		}
+		for _, name := range names {
+			name = path.Join(dir, name)
+			page := pages[name]
+			if page == nil {
+				continue
+			}
+			var buf bytes.Buffer
+			err := t.execute(&buf, page, name, &ErrorPage{
+				StatusCode: statusCode,
+				Status:     http.StatusText(statusCode),
+				Message:    message,
+				Request:    request,
+			})
+			if err != nil {
+				break
+			}
+			writer.Header().Set("Content-Type", contenttype.HTML)
+			writer.Header().Set("X-Content-Type-Options", "nosniff")
+			writer.WriteHeader(statusCode)
+			writer.Write(buf.Bytes()) //#nosec G104
+			return
+		}
+		httperr.RenderErrorHTML(writer, request, statusCode, message)
+	})
+}
+
+// Template is a handler type for functions that return the name
+// of a page template and the data to render it with the Templates
+// of the request's Config or DefaultTemplates.
+// The page is rendered into a buffer, so template errors result
+// in a clean 500 Internal Server Error response handled by httperr.Handle
+// like any error returned by the handler function.
+//
+// Like for HTML, the handler function can call WriteHeader
+// to use a different status code than 200 OK.
+//
+// Example:
+//
+//	respond.DefaultTemplates = templates
+//
+//	http.Handle("/users", respond.Template(func(w http.ResponseWriter, r *http.Request) (string, any, error) {
+//	    users, err := db.GetUsers()
+//	    return "pages/users.html", users, err
+//	}))
+type Template func(http.ResponseWriter, *http.Request) (name string, data any, err error)
+
+// ServeHTTP implements http.Handler for Template.
+// It calls the handler function, handles any error, and renders the page template.
+// If CatchPanics of the request's Config is true, panics are recovered and handled as errors.
+func (handlerFunc Template) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	config := RequestConfig(request)
+	writer, commit := config.bufferResponse(writer)
+	defer commit()
+	if config.CatchPanics {
+		defer config.recoverPanic(writer, request)
+	}
+
+	deferred := &deferredStatusWriter{ResponseWriter: writer}
+	name, data, err := handlerFunc(deferred, request)
+	if config.HandleError(err, writer, request) {
+		return
+	}
+
+	templates := config.templates()
+	if templates == nil {
+		config.HandleError(errors.New("no respond.Templates configured"), writer, request)
+		return
+	}
+	var buf bytes.Buffer
+	err = templates.Render(&buf, name, data)
+	if config.HandleError(err, writer, request) {
+		return
+	}
+
+	config.writeDeferred(deferred, request, contenttype.HTML, buf.Bytes())
+}
@@ -0,0 +1,286 @@
+package respond
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/ungerik/go-httpx/httperr"
+)
+
+func ExampleTemplate() {
+	templates := &Templates{
+		FS: fstest.MapFS{
+			"layouts/base.html": {Data: []byte(`{{define "base"}}<title>{{template "title" .}}</title>{{template "content" .}}{{end}}`)},
+			"pages/hello.html":  {Data: []byte(`{{define "title"}}Hello{{end}}{{define "content"}}<p>Hello {{.}}!</p>{{end}}`)},
+			"errors/4xx.html":   {Data: []byte(`{{define "title"}}{{.Status}}{{end}}{{define "content"}}<p>{{.StatusCode}}: {{.Message}}</p>{{end}}`)},
+		},
+		Layouts: []string{"layouts/*.html"},
+		Pages:   []string{"pages/*.html", "errors/*.html"},
+		Layout:  "base",
+	}
+	if err := templates.Parse(); err != nil {
+		panic(err)
+	}
+	config := &Config{Templates: templates}
+
+	hello := config.Bind(Template(func(w http.ResponseWriter, r *http.Request) (string, any, error) {
+		return "pages/hello.html", "<World>", nil
+	}))
+	recorder := httptest.NewRecorder()
+	hello.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	fmt.Println(recorder.Code, recorder.Body.String())
+
+	missing := config.Bind(Template(func(w http.ResponseWriter, r *http.Request) (string, any, error) {
+		return "pages/missing.html", nil, nil
+	}))
+	recorder = httptest.NewRecorder()
+	missing.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	fmt.Println(recorder.Code)
+
+	failing := config.Bind(Template(func(w http.ResponseWriter, r *http.Request) (string, any, error) {
+		return "", nil, errors.New("never shown")
+	}))
+	recorder = httptest.NewRecorder()
+	failing.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	fmt.Println(recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	templates.ErrorRenderer("errors").RenderError(recorder, nil, http.StatusNotFound, "No such user")
+	fmt.Println(recorder.Code, recorder.Body.String())
+
+	// Output:
+	// 200 <title>Hello</title><p>Hello &lt;World&gt;!</p>
+	// 500
+	// 500
+	// 404 <title>Not Found</title><p>404: No such user</p>
+}
+
+func TestTemplatesParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		fs      fstest.MapFS
+		layouts []string
+		pages   []string
+		wantErr bool
+	}{
+		{"page", fstest.MapFS{"a.html": {Data: []byte("a")}}, nil, []string{"*.html"}, false},
+		{"no pages", fstest.MapFS{"a.html": {Data: []byte("a")}}, nil, []string{"pages/*.html"}, true},
+		{"no patterns", fstest.MapFS{"a.html": {Data: []byte("a")}}, nil, nil, true},
+		{"bad page pattern", fstest.MapFS{}, nil, []string{"["}, true},
+		{"bad layout pattern", fstest.MapFS{"a.html": {Data: []byte("a")}}, []string{"["}, []string{"*.html"}, true},
+		{"page syntax error", fstest.MapFS{"a.html": {Data: []byte("{{.")}}, nil, []string{"*.html"}, true},
+		{"layout syntax error", fstest.MapFS{"l.tmpl": {Data: []byte("{{end}}")}, "a.html": {Data: []byte("a")}}, []string{"*.tmpl"}, []string{"*.html"}, true},
+		{"unknown function", fstest.MapFS{"a.html": {Data: []byte("{{upper .}}")}}, nil, []string{"*.html"}, true},
+		{"layout without matches", fstest.MapFS{"a.html": {Data: []byte("a")}}, []string{"layouts/*.html"}, []string{"*.html"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			templates := &Templates{FS: tt.fs, Layouts: tt.layouts, Pages: tt.pages}
+			err := templates.Parse()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %t", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTemplatesRender(t *testing.T) {
+	fs := fstest.MapFS{
+		"layouts/base.html": {Data: []byte(`{{define "base"}}[{{template "content" .}}]{{end}}`)},
+		"pages/a.html":      {Data: []byte(`{{define "content"}}a {{upper .}}{{end}}`)},
+		"pages/b.html":      {Data: []byte(`{{define "content"}}b{{.Missing}}{{end}}`)},
+		"plain/c.html":      {Data: []byte(`c {{.}}`)},
+	}
+	funcs := template.FuncMap{"upper": strings.ToUpper}
+	tests := []struct {
+		name      string
+		templates *Templates
+		page      string
+		data      any
+		want      string
+		wantErr   bool
+	}{
+		{"layout", &Templates{FS: fs, Layouts: []string{"layouts/*.html"}, Pages: []string{"pages/*.html"}, Layout: "base", Funcs: funcs}, "pages/a.html", "x", "[a X]", false},
+		{"page without layout", &Templates{FS: fs, Pages: []string{"plain/*.html"}}, "plain/c.html", "<x>", "c &lt;x&gt;", false},
+		{"missing page", &Templates{FS: fs, Pages: []string{"plain/*.html"}}, "plain/d.html", nil, "", true},
+		{"missing layout", &Templates{FS: fs, Pages: []string{"pages/*.html"}, Layout: "base", Funcs: funcs}, "pages/a.html", "x", "", true},
+		{"execution error", &Templates{FS: fs, Layouts: []string{"layouts/*.html"}, Pages: []string{"pages/*.html"}, Layout: "base", Funcs: funcs}, "pages/b.html", 1, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.templates.Parse(); err != nil {
+				t.Fatal(err)
+			}
+			var buf strings.Builder
+			err := tt.templates.Render(&buf, tt.page, tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Render() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if !tt.wantErr && buf.String() != tt.want {
+				t.Errorf("Render() = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestTemplatesNotParsed(t *testing.T) {
+	templates := &Templates{FS: fstest.MapFS{"a.html": {Data: []byte("a")}}, Pages: []string{"*.html"}}
+	if templates.Has("a.html") {
+		t.Errorf("Has() = true before Parse")
+	}
+	if err := templates.Render(&strings.Builder{}, "a.html", nil); err == nil {
+		t.Errorf("Render() before Parse succeeded")
+	}
+	templates.Reload = true
+	if !templates.Has("a.html") {
+		t.Errorf("Has() = false with Reload")
+	}
+}
+
+func TestTemplatesReload(t *testing.T) {
+	fs := fstest.MapFS{"a.html": {Data: []byte("v1")}}
+	for _, reload := range []bool{false, true} {
+		t.Run(fmt.Sprintf("Reload=%t", reload), func(t *testing.T) {
+			fs["a.html"] = &fstest.MapFile{Data: []byte("v1")}
+			templates := &Templates{FS: fs, Pages: []string{"*.html"}, Reload: reload}
+			if err := templates.Parse(); err != nil {
+				t.Fatal(err)
+			}
+			fs["a.html"] = &fstest.MapFile{Data: []byte("v2")}
+			var buf strings.Builder
+			if err := templates.Render(&buf, "a.html", nil); err != nil {
+				t.Fatal(err)
+			}
+			want := "v1"
+			if reload {
+				want = "v2"
+			}
+			if buf.String() != want {
+				t.Errorf("Render() = %q, want %q", buf.String(), want)
+			}
+		})
+	}
+}
+
+func TestTemplatesErrorRenderer(t *testing.T) {
+	tests := []struct {
+		name       string
+		fs         fstest.MapFS
+		statusCode int
+		wantBody   string
+	}{
+		{"status code page", fstest.MapFS{"errors/404.html": {Data: []byte("404 {{.Message}}")}, "errors/4xx.html": {Data: []byte("4xx")}}, 404, "404 gone"},
+		{"status class page", fstest.MapFS{"errors/404.html": {Data: []byte("404")}, "errors/4xx.html": {Data: []byte("4xx {{.Status}}")}}, 403, "4xx Forbidden"},
+		{"generic page", fstest.MapFS{"errors/4xx.html": {Data: []byte("4xx")}, "errors/error.html": {Data: []byte("error {{.StatusCode}}")}}, 502, "error 502"},
+		{"request in data", fstest.MapFS{"errors/error.html": {Data: []byte("{{.Request.URL.Path}}")}}, 500, "/path"},
+		{"fallback without page", fstest.MapFS{"errors/4xx.html": {Data: []byte("4xx")}}, 500, "<h1>500 Internal Server Error</h1>"},
+		{"fallback on execution error", fstest.MapFS{"errors/error.html": {Data: []byte("{{.Missing}}")}}, 500, "<h1>500 Internal Server Error</h1>"},
+		{"pages outside dir are ignored", fstest.MapFS{"404.html": {Data: []byte("root")}}, 404, "<h1>404 Not Found</h1>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			templates := &Templates{FS: tt.fs, Pages: []string{"*.html", "errors/*.html"}}
+			if err := templates.Parse(); err != nil {
+				t.Fatal(err)
+			}
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodGet, "/path", nil)
+			templates.ErrorRenderer("errors").RenderError(recorder, request, tt.statusCode, "gone")
+			if recorder.Code != tt.statusCode {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.statusCode)
+			}
+			if got := recorder.Header().Get("Content-Type"); got != "text/html; charset=utf-8" {
+				t.Errorf("Content-Type = %q", got)
+			}
+			if !strings.Contains(recorder.Body.String(), tt.wantBody) {
+				t.Errorf("body = %q, want to contain %q", recorder.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestTemplate(t *testing.T) {
+	templates := &Templates{
+		FS: fstest.MapFS{
+			"ok.html":   {Data: []byte("ok {{.}}")},
+			"fail.html": {Data: []byte("partial {{.Missing}}")},
+		},
+		Pages: []string{"*.html"},
+	}
+	if err := templates.Parse(); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name       string
+		templates  *Templates
+		handler    Template
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "page",
+			templates:  templates,
+			handler:    func(http.ResponseWriter, *http.Request) (string, any, error) { return "ok.html", 1, nil },
+			wantStatus: 200,
+			wantBody:   "ok 1",
+		},
+		{
+			name:      "custom status",
+			templates: templates,
+			handler: func(w http.ResponseWriter, r *http.Request) (string, any, error) {
+				w.WriteHeader(http.StatusCreated)
+				return "ok.html", 2, nil
+			},
+			wantStatus: 201,
+			wantBody:   "ok 2",
+		},
+		{
+			name:       "handler error",
+			templates:  templates,
+			handler:    func(http.ResponseWriter, *http.Request) (string, any, error) { return "ok.html", 1, httperr.NotFound },
+			wantStatus: 404,
+		},
+		{
+			name:       "execution error discards partial output",
+			templates:  templates,
+			handler:    func(http.ResponseWriter, *http.Request) (string, any, error) { return "fail.html", 1, nil },
+			wantStatus: 500,
+		},
+		{
+			name:       "no templates configured",
+			handler:    func(http.ResponseWriter, *http.Request) (string, any, error) { return "ok.html", 1, nil },
+			wantStatus: 500,
+		},
+	}
+	defer func(templates *Templates) { DefaultTemplates = templates }(DefaultTemplates)
+	DefaultTemplates = nil
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.Templates = tt.templates
+			recorder := httptest.NewRecorder()
+			config.Bind(tt.handler).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+			if tt.wantStatus >= 400 {
+				if strings.Contains(recorder.Body.String(), "partial") {
+					t.Errorf("error response contains partial output: %q", recorder.Body)
+				}
+				return
+			}
+			if got := recorder.Header().Get("Content-Type"); got != "text/html; charset=utf-8" {
+				t.Errorf("Content-Type = %q", got)
+			}
+			if got := recorder.Body.String(); got != tt.wantBody {
+				t.Errorf("body = %q, want %q", got, tt.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package respond
+
+import (
+	"net/http"
+	"reflect"
+)
+
+// TypedHandler is implemented by the generic handler types like JSONOf and XMLOf
+// to expose the type of their response for tooling like API documentation
+// or client code generators.
+//
+// Example:
+//
+//	var handler http.Handler = respond.JSONOf[User](getUser)
+//	if typed, ok := handler.(respond.TypedHandler); ok {
+//	    fmt.Println(typed.ResponseType()) // main.User
+//	}
+type TypedHandler interface {
+	http.Handler
+
+	// ResponseType returns the type of the response value.
+	ResponseType() reflect.Type
+}
+
+// TypedInputHandler is implemented by the generic handler types
+// like JSONIn and XMLIn that decode the request body into a typed input.
+type TypedInputHandler interface {
+	TypedHandler
+
+	// RequestType returns the type of the decoded request body.
+	RequestType() reflect.Type
+}
+
+func typeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
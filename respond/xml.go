@@ -3,15 +3,16 @@ package respond
 import (
 	"encoding/xml"
 	"net/http"
+	"reflect"
 
 	"github.com/ungerik/go-httpx/contenttype"
-	"github.com/ungerik/go-httpx/httperr"
 )
 
 // XML is a handler type for functions that return data to be marshaled as XML.
 // The returned data is automatically serialized to XML and written with
 // Content-Type: application/xml. The XML header is automatically prepended.
 // Any error is handled by httperr.Handle.
+// Return a Result to control the status code and headers of the response.
 //
 // Example:
 //
@@ -23,41 +24,157 @@ type XML func(http.ResponseWriter, *http.Request) (response any, err error)
 
 // ServeHTTP implements http.Handler for XML.
 // It calls the handler function, handles any error, and marshals the response to XML.
-// If CatchPanics is true, panics are recovered and handled as errors.
+// If CatchPanics of the request's Config is true, panics are recovered and handled as errors.
 func (handlerFunc XML) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
-	if CatchPanics {
-		defer func() {
-			httperr.HandlePanic(recover(), writer, request)
-		}()
+	config := RequestConfig(request)
+	writer, commit := config.bufferResponse(writer)
+	defer commit()
+	if config.CatchPanics {
+		defer config.recoverPanic(writer, request)
 	}
 
 	response, err := handlerFunc(writer, request)
-	if httperr.Handle(err, writer, request) {
+	if config.HandleError(err, writer, request) {
 		return
 	}
 
-	WriteXML(writer, response)
+	config.WriteXML(writer, request, response)
+}
+
+// XMLOf is the generic counterpart of XML for handler functions
+// that return a typed response to be marshaled as XML.
+// It has the same error handling and panic recovery semantics as XML
+// and implements TypedHandler to expose the response type.
+//
+// Example:
+//
+//	http.Handle("/api/user.xml", respond.XMLOf[*User](func(w http.ResponseWriter, r *http.Request) (*User, error) {
+//	    return db.GetUser(r)
+//	}))
+type XMLOf[T any] func(http.ResponseWriter, *http.Request) (response T, err error)
+
+// ServeHTTP implements http.Handler for XMLOf.
+// It calls the handler function, handles any error, and marshals the response to XML.
+// If CatchPanics of the request's Config is true, panics are recovered and handled as errors.
+func (handlerFunc XMLOf[T]) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	config := RequestConfig(request)
+	writer, commit := config.bufferResponse(writer)
+	defer commit()
+	if config.CatchPanics {
+		defer config.recoverPanic(writer, request)
+	}
+
+	response, err := handlerFunc(writer, request)
+	if config.HandleError(err, writer, request) {
+		return
+	}
+
+	config.WriteXML(writer, request, response)
+}
+
+// ResponseType returns the type T of the response.
+// It implements TypedHandler.
+func (XMLOf[T]) ResponseType() reflect.Type {
+	return typeOf[T]()
+}
+
+// XMLIn is a handler type for functions that receive the request body
+// decoded into a typed input and return a typed response to be marshaled as XML.
+// The body is decoded with DecodeRequestBody according to its Content-Type,
+// so malformed bodies result in 400, too large bodies in 413,
+// and unsupported content types in 415 error responses
+// without calling the handler function.
+// It has the same error handling and panic recovery semantics as XML
+// and implements TypedInputHandler.
+type XMLIn[In, Out any] func(writer http.ResponseWriter, request *http.Request, input In) (response Out, err error)
+
+// ServeHTTP implements http.Handler for XMLIn.
+// It decodes the request body, calls the handler function,
+// handles any error, and marshals the response to XML.
+// If CatchPanics of the request's Config is true, panics are recovered and handled as errors.
+func (handlerFunc XMLIn[In, Out]) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	config := RequestConfig(request)
+	writer, commit := config.bufferResponse(writer)
+	defer commit()
+	if config.CatchPanics {
+		defer config.recoverPanic(writer, request)
+	}
+
+	var input In
+	err := config.DecodeRequestBody(writer, request, &input)
+	if config.HandleError(err, writer, request) {
+		return
+	}
+
+	response, err := handlerFunc(writer, request, input)
+	if config.HandleError(err, writer, request) {
+		return
+	}
+
+	config.WriteXML(writer, request, response)
+}
+
+// RequestType returns the type In of the decoded request body.
+// It implements TypedInputHandler.
+func (XMLIn[In, Out]) RequestType() reflect.Type {
+	return typeOf[In]()
+}
+
+// ResponseType returns the type Out of the response.
+// It implements TypedHandler.
+func (XMLIn[In, Out]) ResponseType() reflect.Type {
+	return typeOf[Out]()
 }
 
 // WriteXML marshals the response to XML and writes it with the appropriate content type.
 // The XML header is automatically prepended. If marshaling fails, an internal server error is written.
 // The response is pretty-printed if PrettyPrint is true.
 func WriteXML(writer http.ResponseWriter, response any) {
-	b, err := EncodeXML(response)
-	if err != nil {
-		httperr.WriteInternalServerError(err, writer)
-		return
-	}
-	writer.Header().Set("Content-Type", contenttype.XML)
-	writer.Write([]byte(xml.Header)) //#nosec G104
-	writer.Write(b)                  //#nosec G104
+	DefaultConfig().WriteXML(writer, nil, response)
 }
 
 // EncodeXML marshals the response to XML bytes.
 // The response is pretty-printed if PrettyPrint is true.
 func EncodeXML(response any) ([]byte, error) {
-	if PrettyPrint {
-		return xml.MarshalIndent(response, "", PrettyPrintIndent)
+	return DefaultConfig().EncodeXML(response)
+}
+
+// WriteXML marshals the response to XML and writes it with the appropriate content type.
+// The XML header is automatically prepended. If marshaling fails, an internal server error is written.
+// The response is pretty-printed if PrettyPrint of the Config is true.
+// If response is a Result, then its status code and headers
+// are written and its body is marshaled.
+// If request is not nil, then the conditional headers of GET and HEAD
+// requests are evaluated and an ETag is added if AutoETag is true.
+func (c *Config) WriteXML(writer http.ResponseWriter, request *http.Request, response any) {
+	c.WriteEncoded(writer, request, xmlEncoder, response)
+}
+
+// EncodeXML marshals the response to XML bytes.
+// The response is pretty-printed if PrettyPrint of the Config is true.
+func (c *Config) EncodeXML(response any) ([]byte, error) {
+	return c.Encode(Encoder{Encode: xml.Marshal, EncodeIndent: xmlMarshalIndent}, response)
+}
+
+// xmlEncoder is used by WriteXML and the Negotiated handler
+// so both write the same document including the XML header
+var xmlEncoder = Encoder{
+	ContentType: contenttype.XML,
+	Encode: func(response any) ([]byte, error) {
+		return withXMLHeader(xml.Marshal(response))
+	},
+	EncodeIndent: func(response any, indent string) ([]byte, error) {
+		return withXMLHeader(xmlMarshalIndent(response, indent))
+	},
+}
+
+func xmlMarshalIndent(response any, indent string) ([]byte, error) {
+	return xml.MarshalIndent(response, "", indent)
+}
+
+func withXMLHeader(body []byte, err error) ([]byte, error) {
+	if err != nil {
+		return nil, err
 	}
-	return xml.Marshal(response)
+	return append([]byte(xml.Header), body...), nil
 }
@@ -0,0 +1,243 @@
+package respond
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ungerik/go-httpx/contenttype"
+)
+
+// YAML is a handler type for functions that return data to be encoded as YAML.
+// The returned data is encoded with EncodeYAML and written with
+// Content-Type: application/yaml. Any error is handled by httperr.Handle.
+// Return a Result to control the status code and headers of the response.
+//
+// Example:
+//
+//	http.Handle("/api/config.yaml", respond.YAML(func(w http.ResponseWriter, r *http.Request) (any, error) {
+//	    return db.GetConfig(r.Context())
+//	}))
+type YAML func(http.ResponseWriter, *http.Request) (response any, err error)
+
+// ServeHTTP implements http.Handler for YAML.
+// It calls the handler function, handles any error, and encodes the response as YAML.
+// If CatchPanics of the request's Config is true, panics are recovered and handled as errors.
+func (handlerFunc YAML) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	config := RequestConfig(request)
+	writer, commit := config.bufferResponse(writer)
+	defer commit()
+	if config.CatchPanics {
+		defer config.recoverPanic(writer, request)
+	}
+
+	response, err := handlerFunc(writer, request)
+	if config.HandleError(err, writer, request) {
+		return
+	}
+
+	config.WriteYAML(writer, request, response)
+}
+
+// WriteYAML encodes the response as YAML and writes it with the appropriate content type.
+// If encoding fails, an internal server error is written.
+func WriteYAML(writer http.ResponseWriter, response any) {
+	DefaultConfig().WriteYAML(writer, nil, response)
+}
+
+// EncodeYAML encodes the response as YAML 1.2 block style document.
+//
+// Values are encoded like by encoding/json, so struct fields
+// are named by their yaml tag or else by their json tag
+// and types implementing json.Marshaler or encoding.TextMarshaler
+// are encoded as their JSON or text representation.
+// Byte slices are encoded as base64 with the !!binary tag.
+// Strings that could be read as another type are quoted.
+func EncodeYAML(response any) ([]byte, error) {
+	value, err := genericValue(response, "yaml")
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	writeYAML(&buf, value, 0)
+	return buf.Bytes(), nil
+}
+
+// WriteYAML encodes the response as YAML and writes it with the appropriate content type.
+// If encoding fails, an internal server error is written.
+// If response is a Result, then its status code and headers
+// are written and its body is encoded.
+// If request is not nil, then the conditional headers of GET and HEAD
+// requests are evaluated and an ETag is added if AutoETag is true.
+func (c *Config) WriteYAML(writer http.ResponseWriter, request *http.Request, response any) {
+	c.WriteEncoded(writer, request, yamlEncoder, response)
+}
+
+var yamlEncoder = Encoder{
+	ContentType: contenttype.YAML,
+	Encode:      EncodeYAML,
+}
+
+// writeYAML writes value as block node with indent spaces
+// for all lines after the first one
+func writeYAML(buf *bytes.Buffer, value any, indent int) {
+	switch x := value.(type) {
+	case genericMap:
+		if len(x) == 0 {
+			buf.WriteString("{}\n")
+			return
+		}
+		for i, entry := range x {
+			if i > 0 {
+				writeYAMLIndent(buf, indent)
+			}
+			writeYAMLString(buf, entry.Key)
+			buf.WriteByte(':')
+			if isYAMLCollection(entry.Value) {
+				buf.WriteByte('\n')
+				writeYAMLIndent(buf, indent+2)
+				writeYAML(buf, entry.Value, indent+2)
+			} else {
+				buf.WriteByte(' ')
+				writeYAML(buf, entry.Value, indent+2)
+			}
+		}
+	case []any:
+		if len(x) == 0 {
+			buf.WriteString("[]\n")
+			return
+		}
+		for i, item := range x {
+			if i > 0 {
+				writeYAMLIndent(buf, indent)
+			}
+			buf.WriteString("- ")
+			writeYAML(buf, item, indent+2)
+		}
+	default:
+		writeYAMLScalar(buf, value)
+		buf.WriteByte('\n')
+	}
+}
+
+func isYAMLCollection(value any) bool {
+	switch x := value.(type) {
+	case genericMap:
+		return len(x) > 0
+	case []any:
+		return len(x) > 0
+	}
+	return false
+}
+
+func writeYAMLIndent(buf *bytes.Buffer, indent int) {
+	for i := 0; i < indent; i++ {
+		buf.WriteByte(' ')
+	}
+}
+
+func writeYAMLScalar(buf *bytes.Buffer, value any) {
+	switch x := value.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		buf.WriteString(strconv.FormatBool(x))
+	case int64:
+		buf.WriteString(strconv.FormatInt(x, 10))
+	case uint64:
+		buf.WriteString(strconv.FormatUint(x, 10))
+	case float32:
+		writeYAMLFloat(buf, float64(x), 32)
+	case float64:
+		writeYAMLFloat(buf, x, 64)
+	case string:
+		writeYAMLString(buf, x)
+	case []byte:
+		buf.WriteString("!!binary ")
+		buf.WriteString(base64.StdEncoding.EncodeToString(x))
+	default:
+		writeYAMLString(buf, fmt.Sprint(x))
+	}
+}
+
+func writeYAMLFloat(buf *bytes.Buffer, f float64, bitSize int) {
+	switch {
+	case math.IsNaN(f):
+		buf.WriteString(".nan")
+	case math.IsInf(f, 1):
+		buf.WriteString(".inf")
+	case math.IsInf(f, -1):
+		buf.WriteString("-.inf")
+	default:
+		buf.WriteString(strconv.FormatFloat(f, 'g', -1, bitSize))
+	}
+}
+
+// writeYAMLString writes s as plain scalar if it can't be
+// mistaken for another type or syntax, else double quoted
+func writeYAMLString(buf *bytes.Buffer, s string) {
+	if isYAMLPlain(s) {
+		buf.WriteString(s)
+		return
+	}
+	buf.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			switch {
+			case r < 0x20 || r == 0x7f:
+				fmt.Fprintf(buf, `\x%02X`, r)
+			case r == utf8.RuneError || !unicode.IsPrint(r) && r != ' ':
+				if r > 0xFFFF {
+					fmt.Fprintf(buf, `\U%08X`, r)
+				} else {
+					fmt.Fprintf(buf, `\u%04X`, r)
+				}
+			default:
+				buf.WriteRune(r)
+			}
+		}
+	}
+	buf.WriteByte('"')
+}
+
+// isYAMLPlain returns true if s can be written as plain scalar
+// that is read as the same string by YAML 1.1 and 1.2 parsers
+func isYAMLPlain(s string) bool {
+	if s == "" || strings.TrimSpace(s) != s || !utf8.ValidString(s) {
+		return false
+	}
+	switch strings.ToLower(s) {
+	case "~", "null", "true", "false", "yes", "no", "on", "off", "y", "n", "<<":
+		return false
+	}
+	// Indicator characters, numbers, dates, and special floats like .inf
+	if strings.ContainsAny(s[:1], "-?:,[]{}#&*!|>'\"%@`+.0123456789") {
+		return false
+	}
+	if strings.Contains(s, ": ") || strings.Contains(s, " #") || strings.HasSuffix(s, ":") {
+		return false
+	}
+	for _, r := range s {
+		if r != ' ' && !unicode.IsPrint(r) {
+			return false
+		}
+	}
+	return true
+}
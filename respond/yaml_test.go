@@ -0,0 +1,102 @@
+package respond
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func ExampleYAML() {
+	type Server struct {
+		Host  string   `json:"host"`
+		Port  int      `json:"port"`
+		Debug bool     `json:"debug,omitempty"`
+		Tags  []string `json:"tags" yaml:"labels"`
+	}
+	handler := YAML(func(w http.ResponseWriter, r *http.Request) (any, error) {
+		return []Server{
+			{Host: "api.example.com", Port: 443, Tags: []string{"public", "true"}},
+			{Host: "localhost", Port: 8080, Debug: true},
+		}, nil
+	})
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/servers", nil))
+	fmt.Println(recorder.Header().Get("Content-Type"))
+	fmt.Print(recorder.Body.String())
+
+	// Output:
+	// application/yaml
+	// - host: api.example.com
+	//   port: 443
+	//   labels:
+	//     - public
+	//     - "true"
+	// - host: localhost
+	//   port: 8080
+	//   debug: true
+	//   labels: null
+}
+
+func TestEncodeYAML(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+		want  string
+	}{
+		{"plain string", "hello world", "hello world\n"},
+		{"empty string", "", `""` + "\n"},
+		{"yes", "yes", `"yes"` + "\n"},
+		{"Off", "Off", `"Off"` + "\n"},
+		{"null", "null", `"null"` + "\n"},
+		{"tilde", "~", `"~"` + "\n"},
+		{"merge key", "<<", `"<<"` + "\n"},
+		{"float string", "1.0", `"1.0"` + "\n"},
+		{"int string", "42", `"42"` + "\n"},
+		{"date string", "2024-01-02", `"2024-01-02"` + "\n"},
+		{"special float string", ".inf", `".inf"` + "\n"},
+		{"negative string", "-1", `"-1"` + "\n"},
+		{"mapping indicator", "a: b", `"a: b"` + "\n"},
+		{"trailing colon", "a:", `"a:"` + "\n"},
+		{"colon without space", "a:b", "a:b\n"},
+		{"comment", "a #b", `"a #b"` + "\n"},
+		{"leading comment", "#a", `"#a"` + "\n"},
+		{"leading space", " a", `" a"` + "\n"},
+		{"multiline", "line1\nline2\r\n", `"line1\nline2\r\n"` + "\n"},
+		{"tab and quotes", "a\t\"b\"\\", `"a\t\"b\"\\"` + "\n"},
+		{"control character", "a\x01", `"a\x01"` + "\n"},
+		{"delete character", "a\x7f", `"a\x7F"` + "\n"},
+		{"non-printable BMP rune", "a\u200b", `"a\u200B"` + "\n"},
+		{"non-printable supplementary rune", "a\U000E0001", `"a\U000E0001"` + "\n"},
+		{"printable supplementary rune", "a\U0001F600", "a\U0001F600\n"},
+		{"invalid UTF-8", "a\xff", `"a\uFFFD"` + "\n"},
+		{"nil", nil, "null\n"},
+		{"bool", true, "true\n"},
+		{"negative int", -42, "-42\n"},
+		{"max uint64", uint64(math.MaxUint64), "18446744073709551615\n"},
+		{"float", 1.5, "1.5\n"},
+		{"float32", float32(0.1), "0.1\n"},
+		{"NaN", math.NaN(), ".nan\n"},
+		{"+Inf", math.Inf(1), ".inf\n"},
+		{"-Inf", math.Inf(-1), "-.inf\n"},
+		{"binary", []byte("hi"), "!!binary aGk=\n"},
+		{"empty slice", []int{}, "[]\n"},
+		{"empty map", map[string]int{}, "{}\n"},
+		{"quoted key", map[string]int{"yes": 1}, `"yes": 1` + "\n"},
+		{"nested", map[string]any{"a": map[string]any{"b": []int{1, 2}, "c": []int{}}}, "a:\n  b:\n    - 1\n    - 2\n  c: []\n"},
+		{"sequence of sequences", [][]int{{1, 2}, {3}}, "- - 1\n  - 2\n- - 3\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EncodeYAML(tt.value)
+			if err != nil {
+				t.Fatalf("EncodeYAML() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("EncodeYAML() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}